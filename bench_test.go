@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/closure"
+	"github.com/relnod/calcgo/interpreter/vm"
 	"github.com/relnod/calcgo/lexer"
 	"github.com/relnod/calcgo/parser"
 )
@@ -67,3 +69,83 @@ func Benchmark3Interpreter(b *testing.B) {
 		interpreter.Interpret(str3)
 	}
 }
+
+func Benchmark1VM(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		vm.Run(str1)
+	}
+}
+
+func Benchmark2VM(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		vm.Run(str2)
+	}
+}
+
+func Benchmark3VM(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		vm.Run(str3)
+	}
+}
+
+func Benchmark1Closure(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		closure.Run(str1)
+	}
+}
+
+func Benchmark2Closure(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		closure.Run(str2)
+	}
+}
+
+func Benchmark3Closure(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		closure.Run(str3)
+	}
+}
+
+func Benchmark2InterpreterReuse(b *testing.B) {
+	i := interpreter.NewInterpreter(str2)
+	for n := 0; n < b.N; n++ {
+		i.GetResult()
+	}
+}
+
+func Benchmark3InterpreterReuse(b *testing.B) {
+	i := interpreter.NewInterpreter(str3)
+	for n := 0; n < b.N; n++ {
+		i.GetResult()
+	}
+}
+
+func Benchmark2VMReuse(b *testing.B) {
+	p, _ := vm.CompileString(str2)
+	m := vm.New(p)
+	for n := 0; n < b.N; n++ {
+		m.Run()
+	}
+}
+
+func Benchmark3VMReuse(b *testing.B) {
+	p, _ := vm.CompileString(str3)
+	m := vm.New(p)
+	for n := 0; n < b.N; n++ {
+		m.Run()
+	}
+}
+
+func Benchmark2ClosureReuse(b *testing.B) {
+	f, _ := closure.CompileString(str2)
+	for n := 0; n < b.N; n++ {
+		f.EvalValues(nil)
+	}
+}
+
+func Benchmark3ClosureReuse(b *testing.B) {
+	f, _ := closure.CompileString(str3)
+	for n := 0; n < b.N; n++ {
+		f.EvalValues(nil)
+	}
+}
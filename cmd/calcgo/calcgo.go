@@ -1,25 +1,147 @@
 package main
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
 	"os"
 
-	"github.com/relnod/calcgo"
+	"github.com/relnod/calcgo/generator"
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/parser"
+	"github.com/relnod/calcgo/printer"
 )
 
+var percentOfValue = flag.Bool("percent-of-value", false,
+	"add percentages to and subtract them from values like a pocket calculator")
+
 func main() {
 	flag.Parse()
 
-	result, errors := calcgo.Calc(flag.Arg(0))
+	// An expression, that is the name of a subcommand, like "fmt", gets
+	// calculated with the calc subcommand.
+	switch flag.Arg(0) {
+	case "calc":
+		runCalc(flag.Arg(1))
+	case "fmt":
+		runFmt(flag.Args()[1:])
+	case "ast":
+		runAST(flag.Args()[1:])
+	case "gen":
+		runGen(flag.Args()[1:])
+	default:
+		runCalc(flag.Arg(0))
+	}
+}
+
+// runCalc calculates the expression and prints the result.
+func runCalc(expression string) {
+	i := interpreter.NewInterpreter(expression)
+	i.SetParseOptions(parseOptions())
+
+	result, errors := i.GetResult()
 	if errors != nil {
-		fmt.Println("Errors have occurred:")
-		for _, err := range errors {
-			fmt.Println(err)
+		exitWithErrors(errors)
+	}
+
+	fmt.Println(result)
+}
+
+// runFmt formats the given expressions. If no expression is given, each line
+// of the standard input gets formatted.
+func runFmt(expressions []string) {
+	if len(expressions) > 0 {
+		for _, expression := range expressions {
+			printFormatted(expression)
 		}
+		return
+	}
 
-		os.Exit(1)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		printFormatted(scanner.Text())
 	}
+	if err := scanner.Err(); err != nil {
+		exitWithErrors([]error{err})
+	}
+}
 
-	fmt.Println(result)
+// runAST renders the ast of an expression as a tree or in the DOT language.
+func runAST(args []string) {
+	flags := flag.NewFlagSet("ast", flag.ExitOnError)
+	dot := flags.Bool("dot", false, "render the ast in the DOT language")
+	optimize := flags.Bool("optimize", false, "render the optimized ast")
+	flags.Parse(args)
+
+	ast, errors := parser.ParseWithOptions(flags.Arg(0), parseOptions())
+	if errors != nil {
+		exitWithErrors(errors)
+	}
+
+	var tree parser.IAST = &ast
+	if *optimize && ast.Node != nil {
+		optimized, err := optimizer.Optimize(&ast)
+		if err != nil {
+			exitWithErrors([]error{err})
+		}
+		tree = optimized
+	}
+
+	if *dot {
+		fmt.Print(printer.Dot(tree))
+		return
+	}
+
+	fmt.Print(printer.Tree(tree))
+}
+
+// runGen generates a Go function from an expression and prints the source.
+func runGen(args []string) {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	pkg := flags.String("pkg", "main", "name of the generated package")
+	fn := flags.String("func", "Calculate", "name of the generated function")
+	flags.Parse(args)
+
+	ast, errors := parser.ParseWithOptions(flags.Arg(0), parseOptions())
+	if errors != nil {
+		exitWithErrors(errors)
+	}
+
+	src, err := generator.Generate(&ast, *pkg, *fn)
+	if err != nil {
+		exitWithErrors([]error{err})
+	}
+
+	fmt.Print(string(src))
+}
+
+// parseOptions returns the parser options, that were selected by the global
+// flags.
+func parseOptions() parser.Options {
+	if *percentOfValue {
+		return parser.Options{Percent: parser.PercentOfValue}
+	}
+
+	return parser.Options{}
+}
+
+// printFormatted formats a single expression and prints it.
+func printFormatted(expression string) {
+	formatted, errors := printer.Format(expression)
+	if errors != nil {
+		exitWithErrors(errors)
+	}
+
+	fmt.Println(formatted)
+}
+
+// exitWithErrors prints all errors and exits.
+func exitWithErrors(errors []error) {
+	fmt.Println("Errors have occurred:")
+	for _, err := range errors {
+		fmt.Println(err)
+	}
+
+	os.Exit(1)
 }
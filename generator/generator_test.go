@@ -0,0 +1,245 @@
+package generator_test
+
+import (
+	"go/ast"
+	"go/importer"
+	goparser "go/parser"
+	gotoken "go/token"
+	"go/types"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/generator"
+	"github.com/relnod/calcgo/generator/internal/fixtures"
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/parser"
+)
+
+func TestGenerator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Generator Suite")
+}
+
+const header = "// Code generated by calcgo. DO NOT EDIT.\n\npackage pricing\n\n"
+
+const importCalculator = "import (\n\t\"github.com/relnod/calcgo/interpreter/calculator\"\n)\n\n"
+
+var _ = Describe("GenerateString()", func() {
+	DescribeTable("generates Go source",
+		func(str string, expected string) {
+			src, errs := generator.GenerateString(str, "pricing", "Price")
+			Expect(errs).To(BeNil())
+			Expect(string(src)).To(Equal(expected))
+
+			_, err := goparser.ParseFile(gotoken.NewFileSet(), "price.go", src, 0)
+			Expect(err).To(BeNil())
+		},
+		Entry("empty", "", header+
+			"// Price calculates \"\".\n"+
+			"func Price() (float64, error) {\n"+
+			"\treturn 0.0, nil\n"+
+			"}\n"),
+		Entry("constant", "(1 + 2) * 0x1F / 2^2", header+
+			"// Price calculates \"(1 + 2) * 0x1F / 2^2\".\n"+
+			"func Price() (float64, error) {\n"+
+			"\treturn 23.25, nil\n"+
+			"}\n"),
+		Entry("variables", "a * (1 + b) - c", header+
+			"// Price calculates \"a * (1 + b) - c\".\n"+
+			"func Price(a, b, c float64) (float64, error) {\n"+
+			"\treturn a*(1.0+b) - c, nil\n"+
+			"}\n"),
+		Entry("brackets", "a - (b - c) * (d + e)", header+
+			"// Price calculates \"a - (b - c) * (d + e)\".\n"+
+			"func Price(a, b, c, d, e float64) (float64, error) {\n"+
+			"\treturn a - (b-c)*(d+e), nil\n"+
+			"}\n"),
+		Entry("constant divisor", "a / 4", header+
+			"// Price calculates \"a / 4\".\n"+
+			"func Price(a float64) (float64, error) {\n"+
+			"\treturn a / 4.0, nil\n"+
+			"}\n"),
+		Entry("variable divisor", "a / (b - 1) / c", header+importCalculator+
+			"// Price calculates \"a / (b - 1) / c\".\n"+
+			"func Price(a, b, c float64) (float64, error) {\n"+
+			"\tdivisor := b - 1.0\n"+
+			"\tif divisor == 0 {\n"+
+			"\t\treturn 0, calculator.ErrorDivisionByZero\n"+
+			"\t}\n"+
+			"\tdivisor_1 := c\n"+
+			"\tif divisor_1 == 0 {\n"+
+			"\t\treturn 0, calculator.ErrorDivisionByZero\n"+
+			"\t}\n"+
+			"\treturn a / divisor / divisor_1, nil\n"+
+			"}\n"),
+		Entry("functions", "sqrt(a) + sin(b * 2)", header+
+			"import (\n\t\"math\"\n)\n\n"+
+			"// Price calculates \"sqrt(a) + sin(b * 2)\".\n"+
+			"func Price(a, b float64) (float64, error) {\n"+
+			"\treturn math.Sqrt(a) + math.Sin(b*2.0), nil\n"+
+			"}\n"),
+		Entry("modulo and bitwise operators", "a % 2 + (b | 1) + c ^ 3 & d", header+importCalculator+
+			"// Price calculates \"a % 2 + b | 1 + c ^ 3 & d\".\n"+
+			"func Price(a, b, c, d float64) (float64, error) {\n"+
+			"\tor, err := calculator.Or(b, 1.0)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\txor, err := calculator.Xor(c, 3.0)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\tand, err := calculator.And(xor, d)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\treturn calculator.Mod(a, 2.0) + or + and, nil\n"+
+			"}\n"),
+		Entry("shifts and bitwise not", "~a & 0xff << b >>> 2 >> 1", header+importCalculator+
+			"// Price calculates \"~a & 0xff << b >>> 2 >> 1\".\n"+
+			"func Price(a, b float64) (float64, error) {\n"+
+			"\tbitnot, err := calculator.BitNot(a)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\tand, err := calculator.And(bitnot, 255.0)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\tshiftleft, err := calculator.ShiftLeft(and, b)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\tunsignedshiftright, err := calculator.UnsignedShiftRight(shiftleft, 2.0)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\tshiftright, err := calculator.ShiftRight(unsignedshiftright, 1.0)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\treturn shiftright, nil\n"+
+			"}\n"),
+		Entry("percent", "a * 15% + (a - b)%", header+
+			"// Price calculates \"a * 15% + (a - b)%\".\n"+
+			"func Price(a, b float64) (float64, error) {\n"+
+			"\treturn a*0.15 + (a-b)/100.0, nil\n"+
+			"}\n"),
+		Entry("variable modulo divisor", "a % b", header+importCalculator+
+			"// Price calculates \"a % b\".\n"+
+			"func Price(a, b float64) (float64, error) {\n"+
+			"\tdivisor := b\n"+
+			"\tif divisor == 0 {\n"+
+			"\t\treturn 0, calculator.ErrorDivisionByZero\n"+
+			"\t}\n"+
+			"\treturn calculator.Mod(a, divisor), nil\n"+
+			"}\n"),
+		Entry("functions with errors", "binom(a, 2) * a! + err", header+importCalculator+
+			"// Price calculates \"binom(a, 2) * a! + err\".\n"+
+			"func Price(a, err_1 float64) (float64, error) {\n"+
+			"\tbinom, err := calculator.Binom(a, 2.0)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\tfactorial, err := calculator.Factorial(a)\n"+
+			"\tif err != nil {\n"+
+			"\t\treturn 0, err\n"+
+			"\t}\n"+
+			"\treturn binom*factorial + err_1, nil\n"+
+			"}\n"),
+		Entry("blank identifier", "_ + 1", header+
+			"// Price calculates \"_ + 1\".\n"+
+			"func Price(__1 float64) (float64, error) {\n"+
+			"\treturn __1 + 1.0, nil\n"+
+			"}\n"),
+		Entry("reserved names", "math + if * divisor", header+
+			"// Price calculates \"math + if * divisor\".\n"+
+			"func Price(divisor, if_1, math_1 float64) (float64, error) {\n"+
+			"\treturn math_1 + if_1*divisor, nil\n"+
+			"}\n"),
+	)
+
+	DescribeTable("generates code, that type checks",
+		func(str string) {
+			src, errs := generator.GenerateString(str, "pricing", "Price")
+			Expect(errs).To(BeNil())
+
+			fset := gotoken.NewFileSet()
+			file, err := goparser.ParseFile(fset, "price.go", src, 0)
+			Expect(err).To(BeNil())
+
+			conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+			_, err = conf.Check("pricing", fset, []*ast.File{file}, nil)
+			Expect(err).To(BeNil())
+		},
+		Entry("blank identifier", "_ + 1"),
+		Entry("blank identifiers", "_ * __1 - _1"),
+		Entry("reserved names", "math + float64 * nil"),
+		Entry("functions", "sqrt(_) / (a - 1)"),
+	)
+
+	DescribeTable("fails",
+		func(str string, pkg string, fn string, expErrs []error) {
+			src, errs := generator.GenerateString(str, pkg, fn)
+			Expect(src).To(BeNil())
+			Expect(errs).To(Equal(expErrs))
+		},
+		Entry("invalid package", "1", "my-pkg", "Price", []error{generator.ErrorInvalidIdentifier}),
+		Entry("keyword as function", "1", "pricing", "func", []error{generator.ErrorInvalidIdentifier}),
+		Entry("parser error", "1 + $", "pricing", "Price", []error{parser.ErrorExpectedNumberOrVariable}),
+		Entry("constant division by zero", "1 / 0", "pricing", "Price", []error{calculator.ErrorDivisionByZero}),
+		Entry("constant bitwise operator with decimal", "a + 1.5 & 1", "pricing", "Price", []error{calculator.ErrorNotAnInteger}),
+	)
+})
+
+// inputs are the values of a and b, that the generated functions get called
+// with.
+var inputs = []float64{-7, -2.5, -1, math.Copysign(0, -1), 0, 1, 2, 3, 5, 7.5, 64, 1e20}
+
+var _ = Describe("generated code", func() {
+	DescribeTable("calculates the same as the interpreter",
+		func(file string, fn string, str string, f func(a, b float64) (float64, error)) {
+			src, errs := generator.GenerateString(str, "fixtures", fn)
+			Expect(errs).To(BeNil())
+
+			// The fixtures get regenerated with go generate.
+			fixture, err := ioutil.ReadFile(filepath.Join("internal", "fixtures", file))
+			Expect(err).To(BeNil())
+			Expect(string(fixture)).To(Equal(string(src)), "fixture %s is outdated", file)
+
+			for _, a := range inputs {
+				for _, b := range inputs {
+					i := interpreter.NewInterpreter(str)
+					i.SetVar("a", a)
+					i.SetVar("b", b)
+					expected, expErrs := i.GetResult()
+
+					result, err := f(a, b)
+					if expErrs != nil {
+						Expect(err).To(Equal(expErrs[0]), "a = %v, b = %v", a, b)
+						continue
+					}
+
+					Expect(err).To(BeNil(), "a = %v, b = %v", a, b)
+					Expect(math.Float64bits(result)).To(Equal(math.Float64bits(expected)),
+						"a = %v, b = %v: %v != %v", a, b, result, expected)
+				}
+			}
+		},
+		Entry("modulo", "mod.go", "Mod", "a % b", fixtures.Mod),
+		Entry("division", "division.go", "Division", "a / b - sqrt(a)", fixtures.Division),
+		Entry("shifts", "shifts.go", "Shifts", "(a << b) + (a >> b) - (a >>> b)", fixtures.Shifts),
+		Entry("bitwise operators", "bitwise.go", "Bitwise", "a & b | a ^ ~b", fixtures.Bitwise),
+		Entry("percent", "percent.go", "Percent", "a% * b - b% + (a + 50)%", fixtures.Percent),
+		Entry("integer functions", "integer.go", "Integer",
+			"perm(a, b) + binom(a, b) - gcd(a, b) * lcm(a, b) + mod(a, b)", fixtures.Integer),
+		Entry("factorial", "factorial.go", "Factorial", "a! / gamma(b) + isprime(a)", fixtures.Factorial),
+	)
+})
@@ -0,0 +1,28 @@
+// Code generated by calcgo. DO NOT EDIT.
+
+package fixtures
+
+import (
+	"github.com/relnod/calcgo/interpreter/calculator"
+)
+
+// Bitwise calculates "a & b | a ^ ~b".
+func Bitwise(a, b float64) (float64, error) {
+	and, err := calculator.And(a, b)
+	if err != nil {
+		return 0, err
+	}
+	or, err := calculator.Or(and, a)
+	if err != nil {
+		return 0, err
+	}
+	bitnot, err := calculator.BitNot(b)
+	if err != nil {
+		return 0, err
+	}
+	xor, err := calculator.Xor(or, bitnot)
+	if err != nil {
+		return 0, err
+	}
+	return xor, nil
+}
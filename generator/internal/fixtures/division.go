@@ -0,0 +1,17 @@
+// Code generated by calcgo. DO NOT EDIT.
+
+package fixtures
+
+import (
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"math"
+)
+
+// Division calculates "a / b - sqrt(a)".
+func Division(a, b float64) (float64, error) {
+	divisor := b
+	if divisor == 0 {
+		return 0, calculator.ErrorDivisionByZero
+	}
+	return a/divisor - math.Sqrt(a), nil
+}
@@ -0,0 +1,12 @@
+// Package fixtures contains functions, that were generated by the generator.
+// The tests of the generator run them and compare their results with the
+// results of the interpreter.
+package fixtures
+
+//go:generate sh -c "go run ../../../cmd/calcgo gen -pkg fixtures -func Mod 'a % b' > mod.go"
+//go:generate sh -c "go run ../../../cmd/calcgo gen -pkg fixtures -func Division 'a / b - sqrt(a)' > division.go"
+//go:generate sh -c "go run ../../../cmd/calcgo gen -pkg fixtures -func Shifts '(a << b) + (a >> b) - (a >>> b)' > shifts.go"
+//go:generate sh -c "go run ../../../cmd/calcgo gen -pkg fixtures -func Bitwise 'a & b | a ^ ~b' > bitwise.go"
+//go:generate sh -c "go run ../../../cmd/calcgo gen -pkg fixtures -func Percent 'a% * b - b% + (a + 50)%' > percent.go"
+//go:generate sh -c "go run ../../../cmd/calcgo gen -pkg fixtures -func Integer 'perm(a, b) + binom(a, b) - gcd(a, b) * lcm(a, b) + mod(a, b)' > integer.go"
+//go:generate sh -c "go run ../../../cmd/calcgo gen -pkg fixtures -func Factorial 'a! / gamma(b) + isprime(a)' > factorial.go"
@@ -0,0 +1,28 @@
+// Code generated by calcgo. DO NOT EDIT.
+
+package fixtures
+
+import (
+	"github.com/relnod/calcgo/interpreter/calculator"
+)
+
+// Factorial calculates "a! / gamma(b) + isprime(a)".
+func Factorial(a, b float64) (float64, error) {
+	factorial, err := calculator.Factorial(a)
+	if err != nil {
+		return 0, err
+	}
+	gamma, err := calculator.Gamma(b)
+	if err != nil {
+		return 0, err
+	}
+	divisor := gamma
+	if divisor == 0 {
+		return 0, calculator.ErrorDivisionByZero
+	}
+	isprime, err := calculator.IsPrime(a)
+	if err != nil {
+		return 0, err
+	}
+	return factorial/divisor + isprime, nil
+}
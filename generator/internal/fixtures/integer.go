@@ -0,0 +1,32 @@
+// Code generated by calcgo. DO NOT EDIT.
+
+package fixtures
+
+import (
+	"github.com/relnod/calcgo/interpreter/calculator"
+)
+
+// Integer calculates "perm(a, b) + binom(a, b) - gcd(a, b) * lcm(a, b) + mod(a, b)".
+func Integer(a, b float64) (float64, error) {
+	perm, err := calculator.Perm(a, b)
+	if err != nil {
+		return 0, err
+	}
+	binom, err := calculator.Binom(a, b)
+	if err != nil {
+		return 0, err
+	}
+	gcd, err := calculator.Gcd(a, b)
+	if err != nil {
+		return 0, err
+	}
+	lcm, err := calculator.Lcm(a, b)
+	if err != nil {
+		return 0, err
+	}
+	floormod, err := calculator.FloorMod(a, b)
+	if err != nil {
+		return 0, err
+	}
+	return perm + binom - gcd*lcm + floormod, nil
+}
@@ -0,0 +1,16 @@
+// Code generated by calcgo. DO NOT EDIT.
+
+package fixtures
+
+import (
+	"github.com/relnod/calcgo/interpreter/calculator"
+)
+
+// Mod calculates "a % b".
+func Mod(a, b float64) (float64, error) {
+	divisor := b
+	if divisor == 0 {
+		return 0, calculator.ErrorDivisionByZero
+	}
+	return calculator.Mod(a, divisor), nil
+}
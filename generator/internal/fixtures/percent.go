@@ -0,0 +1,8 @@
+// Code generated by calcgo. DO NOT EDIT.
+
+package fixtures
+
+// Percent calculates "a% * b - b% + (a + 50)%".
+func Percent(a, b float64) (float64, error) {
+	return a/100.0*b - b/100.0 + (a+50.0)/100.0, nil
+}
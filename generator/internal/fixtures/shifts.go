@@ -0,0 +1,24 @@
+// Code generated by calcgo. DO NOT EDIT.
+
+package fixtures
+
+import (
+	"github.com/relnod/calcgo/interpreter/calculator"
+)
+
+// Shifts calculates "a << b + a >> b - a >>> b".
+func Shifts(a, b float64) (float64, error) {
+	shiftleft, err := calculator.ShiftLeft(a, b)
+	if err != nil {
+		return 0, err
+	}
+	shiftright, err := calculator.ShiftRight(a, b)
+	if err != nil {
+		return 0, err
+	}
+	unsignedshiftright, err := calculator.UnsignedShiftRight(a, b)
+	if err != nil {
+		return 0, err
+	}
+	return shiftleft + shiftright - unsignedshiftright, nil
+}
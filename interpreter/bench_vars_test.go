@@ -1,6 +1,7 @@
 package interpreter_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/relnod/calcgo/interpreter"
@@ -49,3 +50,56 @@ func BenchmarkInterpreterVars2(b *testing.B) {
 		i.GetResult()
 	}
 }
+
+func BenchmarkInterpreterVarsMemo(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		i := interpreter.NewInterpreter("(a + 2) * 4 - (4 / 6)")
+		i.EnableMemoization()
+		i.SetVar("a", 1.0)
+		i.GetResult()
+		i.SetVar("a", 2.0)
+		i.GetResult()
+		i.SetVar("a", 3.0)
+		i.GetResult()
+		i.SetVar("a", 4.0)
+		i.GetResult()
+		i.SetVar("a", 5.0)
+		i.GetResult()
+	}
+}
+
+// largeExpression returns an expression with 26 * 26 terms, where each term
+// depends on a different variable.
+func largeExpression() (string, []string) {
+	var terms, names []string
+	for _, c1 := range "abcdefghijklmnopqrstuvwxyz" {
+		for _, c2 := range "abcdefghijklmnopqrstuvwxyz" {
+			name := string(c1) + string(c2)
+			names = append(names, name)
+			terms = append(terms, "("+name+" * 2.5 + 3) / (4 - 1.5)")
+		}
+	}
+
+	return strings.Join(terms, " + "), names
+}
+
+func benchmarkInterpreterVarsLarge(b *testing.B, memo bool) {
+	str, names := largeExpression()
+	i := interpreter.NewInterpreter(str)
+	if memo {
+		i.EnableMemoization()
+	}
+	for _, name := range names {
+		i.SetVar(name, 1.0)
+	}
+	i.GetResult()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		i.SetVar("mm", float64(n))
+		i.GetResult()
+	}
+}
+
+func BenchmarkInterpreterVarsLarge(b *testing.B)     { benchmarkInterpreterVarsLarge(b, false) }
+func BenchmarkInterpreterVarsLargeMemo(b *testing.B) { benchmarkInterpreterVarsLarge(b, true) }
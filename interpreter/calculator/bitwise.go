@@ -0,0 +1,131 @@
+package calculator
+
+// The bitwise operators work on the two's complement of 64 bit integers. Their
+// operands have to be integers, that fit into an int64. Results with more than
+// 53 significant bits get rounded to the nearest float64.
+
+// Or returns the bitwise or of a and b. Returns an error if a or b are not
+// integers.
+func Or(a, b float64) (float64, error) {
+	x, y, err := toInt64s(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(x | y), nil
+}
+
+// Xor returns the bitwise exclusive or of a and b. Returns an error if a or b
+// are not integers.
+func Xor(a, b float64) (float64, error) {
+	x, y, err := toInt64s(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(x ^ y), nil
+}
+
+// And returns the bitwise and of a and b. Returns an error if a or b are not
+// integers.
+func And(a, b float64) (float64, error) {
+	x, y, err := toInt64s(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(x & y), nil
+}
+
+// BitNot returns the bitwise complement of a, so BitNot(5) is -6. Returns an
+// error if a is not an integer.
+func BitNot(a float64) (float64, error) {
+	x, err := toInt64(a)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(^x), nil
+}
+
+// ShiftLeft returns a shifted left by n bits. Returns an error if a or n are
+// not integers, if n is negative or if bits of a get shifted out of an int64.
+func ShiftLeft(a, n float64) (float64, error) {
+	x, count, err := toShiftOperands(a, n)
+	if err != nil {
+		return 0, err
+	}
+
+	result := x << count
+	if result>>count != x {
+		return 0, ErrorOverflow
+	}
+
+	return float64(result), nil
+}
+
+// ShiftRight returns a shifted right by n bits. Like in Go, the sign of a gets
+// kept, so ShiftRight(-8, 1) is -4. Returns an error if a or n are not
+// integers or if n is negative.
+func ShiftRight(a, n float64) (float64, error) {
+	x, count, err := toShiftOperands(a, n)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(x >> count), nil
+}
+
+// UnsignedShiftRight returns a shifted right by n bits, filling the left bits
+// with zeros. A negative a gets shifted as an unsigned integer, so
+// UnsignedShiftRight(-1, 60) is 15. Returns an error if a or n are not
+// integers or if n is negative.
+func UnsignedShiftRight(a, n float64) (float64, error) {
+	x, count, err := toShiftOperands(a, n)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(uint64(x) >> count), nil
+}
+
+// toInt64 converts x to an int64. Returns an error if x is not an integer or
+// doesn't fit into an int64.
+func toInt64(x float64) (int64, error) {
+	if !isInteger(x) {
+		return 0, ErrorNotAnInteger
+	}
+	if x < -1<<63 || x >= 1<<63 {
+		return 0, ErrorOutOfDomain
+	}
+
+	return int64(x), nil
+}
+
+// toInt64s converts a and b to int64s.
+func toInt64s(a, b float64) (int64, int64, error) {
+	x, err := toInt64(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := toInt64(b)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return x, y, nil
+}
+
+// toShiftOperands converts the operands of a shift. Returns an error if the
+// shift count n is negative.
+func toShiftOperands(a, n float64) (int64, uint64, error) {
+	x, count, err := toInt64s(a, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	if count < 0 {
+		return 0, 0, ErrorOutOfDomain
+	}
+
+	return x, uint64(count), nil
+}
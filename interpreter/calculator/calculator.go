@@ -16,12 +16,23 @@ var (
 	ErrorInvalidBinary      = errors.New("Invalid Binary")
 	ErrorInvalidHexadecimal = errors.New("Invalid Hexadecimal")
 	ErrorInvalidExponential = errors.New("Invalid Exponential")
+	ErrorInvalidOctal       = errors.New("Invalid Octal")
+	ErrorInvalidScientific  = errors.New("Invalid Scientific")
+	ErrorInvalidHexFloat    = errors.New("Invalid Hexadecimal Float")
 	ErrorDivisionByZero     = errors.New("Division by zero")
+	ErrorNotAnInteger       = errors.New("Argument is not an integer")
+	ErrorOutOfDomain        = errors.New("Argument is out of domain")
+	ErrorOverflow           = errors.New("Result overflows")
 )
 
 // ConvertInteger converts an integer string to a float64.
 // Returns an error if conversion failed.
 func ConvertInteger(value string) (float64, error) {
+	value, ok := removeSeparators(value)
+	if !ok {
+		return 0, ErrorInvalidInteger
+	}
+
 	integer, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
 		return 0, ErrorInvalidInteger
@@ -42,9 +53,14 @@ func ConvertDecimal(value string) (float64, error) {
 // ConvertBin converts a binary string to a float64.
 // Returns an error if conversion failed.
 func ConvertBin(value string) (float64, error) {
-	val := strings.Replace(value, "0b", "", 1)
+	// Remove the separators before the prefix, so an underscore may follow
+	// the prefix.
+	val, ok := removeSeparators(value)
+	if !ok {
+		return 0, ErrorInvalidBinary
+	}
 
-	bin, err := strconv.ParseInt(val, 2, 64)
+	bin, err := strconv.ParseInt(strings.Replace(val, "0b", "", 1), 2, 64)
 	if err != nil {
 		return 0, ErrorInvalidBinary
 	}
@@ -71,17 +87,17 @@ func ConvertExponential(value string) (float64, error) {
 		return 0, ErrorInvalidExponential
 	}
 
-	base, err := strconv.Atoi(splitted[0])
+	base, err := ConvertInteger(splitted[0])
 	if err != nil {
 		return 0, ErrorInvalidExponential
 	}
 
-	exponent, err := strconv.Atoi(splitted[1])
+	exponent, err := ConvertInteger(splitted[1])
 	if err != nil {
 		return 0, ErrorInvalidExponential
 	}
 
-	res := math.Pow(float64(base), float64(exponent))
+	res := math.Pow(base, exponent)
 	if math.IsInf(res, 1) {
 		return 0, ErrorInvalidExponential
 	}
@@ -89,6 +105,66 @@ func ConvertExponential(value string) (float64, error) {
 	return res, nil
 }
 
+// ConvertOct converts an octal string with the prefix "0o" to a float64.
+// Returns an error if conversion failed.
+func ConvertOct(value string) (float64, error) {
+	octal, err := strconv.ParseInt(value, 0, 64)
+	if err != nil || !strings.Contains(value, "0o") {
+		return 0, ErrorInvalidOctal
+	}
+
+	return float64(octal), nil
+}
+
+// ConvertScientific converts a number in scientific notation, like "1.5e-3",
+// to a float64.
+// Returns an error if conversion failed.
+func ConvertScientific(value string) (float64, error) {
+	scientific, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, ErrorInvalidScientific
+	}
+
+	return scientific, nil
+}
+
+// ConvertHexFloat converts a hexadecimal floating-point number, like
+// "0x1.8p3", to a float64.
+// Returns an error if conversion failed.
+func ConvertHexFloat(value string) (float64, error) {
+	hexFloat, err := strconv.ParseFloat(value, 64)
+	if err != nil || !strings.ContainsAny(value, "pP") {
+		return 0, ErrorInvalidHexFloat
+	}
+
+	return hexFloat, nil
+}
+
+// removeSeparators removes the underscores, that separate digits. Like in Go,
+// an underscore has to stand between two digits or between the base prefix
+// and a digit. Returns false, if an underscore is misplaced.
+func removeSeparators(value string) (string, bool) {
+	if !strings.Contains(value, "_") {
+		return value, true
+	}
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '_' {
+			continue
+		}
+		if i == 0 || i == len(value)-1 || !isAlphaNumeric(value[i-1]) || !isAlphaNumeric(value[i+1]) {
+			return "", false
+		}
+	}
+
+	return strings.Replace(value, "_", "", -1), true
+}
+
+// isAlphaNumeric checks if b is an ASCII letter or digit.
+func isAlphaNumeric(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
 // ConvertLiteral converts a atring literal to a float.
 func ConvertLiteral(value string, nodeType parser.NodeType) (float64, error) {
 	switch nodeType {
@@ -100,6 +176,12 @@ func ConvertLiteral(value string, nodeType parser.NodeType) (float64, error) {
 		return ConvertBin(value)
 	case parser.NHex:
 		return ConvertHex(value)
+	case parser.NOct:
+		return ConvertOct(value)
+	case parser.NSci:
+		return ConvertScientific(value)
+	case parser.NHexFloat:
+		return ConvertHexFloat(value)
 	}
 
 	return ConvertExponential(value)
@@ -122,30 +204,47 @@ func CalculateOperator(left, right float64, nodeType parser.NodeType) (float64,
 		}
 		result = left / right
 	case parser.NMod:
-		for {
-			if left < right {
-				break
-			}
-
-			left -= right
+		if right == 0 {
+			return 0, ErrorDivisionByZero
 		}
-		result = float64(left)
+		result = Mod(left, right)
 	case parser.NOr:
-		result = float64(int(left) | int(right))
+		return Or(left, right)
 	case parser.NXor:
-		result = float64(int(left) ^ int(right))
+		return Xor(left, right)
 	case parser.NAnd:
-		result = float64(int(left) & int(right))
+		return And(left, right)
+	case parser.NShiftLeft:
+		return ShiftLeft(left, right)
+	case parser.NShiftRight:
+		return ShiftRight(left, right)
+	case parser.NUnsignedShiftRight:
+		return UnsignedShiftRight(left, right)
 	}
 
 	return result, nil
 }
 
+// Mod returns the remainder of the truncated division of left by right. The
+// result has the sign of left, so Mod(-7, 3) is -1. Like math.Mod, the result
+// is NaN if right is zero. See FloorMod for the floored division.
+func Mod(left, right float64) float64 {
+	return math.Mod(left, right)
+}
+
 // CalculateFunction calculates the result of a function.
 func CalculateFunction(arg float64, nodeType parser.NodeType) (float64, error) {
 	var result float64
 
 	switch nodeType {
+	case parser.NFactorial:
+		return Factorial(arg)
+	case parser.NFnGamma:
+		return Gamma(arg)
+	case parser.NFnIsPrime:
+		return IsPrime(arg)
+	case parser.NBitNot:
+		return BitNot(arg)
 	case parser.NFnSqrt:
 		result = math.Sqrt(arg)
 	case parser.NFnSin:
@@ -154,7 +253,28 @@ func CalculateFunction(arg float64, nodeType parser.NodeType) (float64, error) {
 		result = math.Cos(arg)
 	case parser.NFnTan:
 		result = math.Tan(arg)
+	case parser.NPercent:
+		result = arg / 100
 	}
 
 	return result, nil
 }
+
+// CalculateBinaryFunction calculates the result of a function with two
+// arguments.
+func CalculateBinaryFunction(left, right float64, nodeType parser.NodeType) (float64, error) {
+	switch nodeType {
+	case parser.NFnBinom:
+		return Binom(left, right)
+	case parser.NFnPerm:
+		return Perm(left, right)
+	case parser.NFnGcd:
+		return Gcd(left, right)
+	case parser.NFnLcm:
+		return Lcm(left, right)
+	case parser.NFnMod:
+		return FloorMod(left, right)
+	}
+
+	return 0, nil
+}
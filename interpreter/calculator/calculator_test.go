@@ -37,6 +37,10 @@ var _ = DescribeTable("ConvertInteger()",
 	Entry("works with correct integer", "123", 123.0, nil),
 	Entry("handles invalid integer", "a", 0.0, calculator.ErrorInvalidInteger),
 	Entry("handles overflow", intOverflow, 0.0, calculator.ErrorInvalidInteger),
+	Entry("works with separators", "1_000", 1000.0, nil),
+	Entry("handles leading separator", "_1", 0.0, calculator.ErrorInvalidInteger),
+	Entry("handles trailing separator", "1_", 0.0, calculator.ErrorInvalidInteger),
+	Entry("handles double separator", "1__0", 0.0, calculator.ErrorInvalidInteger),
 )
 
 var _ = DescribeTable("ConvertDecimal()",
@@ -52,6 +56,8 @@ var _ = DescribeTable("ConvertDecimal()",
 	Entry("works with correct decimal", "123.456", 123.456, nil),
 	Entry("handles invalid decimal", "a", 0.0, calculator.ErrorInvalidDecimal),
 	Entry("handles overflow", decOverflow, 0.0, calculator.ErrorInvalidDecimal),
+	Entry("works with leading dot", ".5", 0.5, nil),
+	Entry("works with separators", "1_000.5", 1000.5, nil),
 )
 
 var _ = DescribeTable("ConvertHex()",
@@ -67,6 +73,10 @@ var _ = DescribeTable("ConvertHex()",
 	Entry("works with correct hex", "0x1A", 26.0, nil),
 	Entry("handles invalid hex", "0xH", 0.0, calculator.ErrorInvalidHexadecimal),
 	Entry("handles overflow", hexOverflow, 0.0, calculator.ErrorInvalidHexadecimal),
+	Entry("works with lowercase hex", "0xff", 255.0, nil),
+	Entry("works with separators", "0xFF_FF", 65535.0, nil),
+	Entry("works with separator after prefix", "0x_ff", 255.0, nil),
+	Entry("handles double separator after prefix", "0x__ff", 0.0, calculator.ErrorInvalidHexadecimal),
 )
 
 var _ = DescribeTable("ConvertBin()",
@@ -82,6 +92,11 @@ var _ = DescribeTable("ConvertBin()",
 	Entry("works with correct binary", "0b10", 2.0, nil),
 	Entry("handles invalid binary", "0b2", 0.0, calculator.ErrorInvalidBinary),
 	Entry("handles overflow", binOverflow, 0.0, calculator.ErrorInvalidBinary),
+	Entry("works with separators", "0b1_0", 2.0, nil),
+	Entry("handles invalid separator", "0b1__0", 0.0, calculator.ErrorInvalidBinary),
+	Entry("works with separator after prefix", "0b_1", 1.0, nil),
+	Entry("works with negative number and separator after prefix", "-0b_10", -2.0, nil),
+	Entry("handles double separator after prefix", "0b__1", 0.0, calculator.ErrorInvalidBinary),
 )
 
 var _ = DescribeTable("ConvertExponential()",
@@ -97,6 +112,59 @@ var _ = DescribeTable("ConvertExponential()",
 	Entry("works with correct exponential", "2^2", 4.0, nil),
 	Entry("handles invalid exponential", "2^$", 0.0, calculator.ErrorInvalidExponential),
 	Entry("handles overflow", expOverflow, 0.0, calculator.ErrorInvalidExponential),
+	Entry("works with separators", "1_0^2", 100.0, nil),
+)
+
+var _ = DescribeTable("ConvertOct()",
+	func(in string, expRes float64, expErr error) {
+		result, err := calculator.ConvertOct(in)
+		Expect(result).To(BeNumerically("==", expRes))
+		if expErr != nil {
+			Expect(err).To(Equal(expErr))
+		} else {
+			Expect(err).To(BeNil())
+		}
+	},
+	Entry("works with correct octal", "0o17", 15.0, nil),
+	Entry("works with separators", "0o1_7", 15.0, nil),
+	Entry("works with negative octal", "-0o17", -15.0, nil),
+	Entry("works with separator after prefix", "0o_17", 15.0, nil),
+	Entry("handles double separator after prefix", "0o__17", 0.0, calculator.ErrorInvalidOctal),
+	Entry("handles invalid octal", "0o8", 0.0, calculator.ErrorInvalidOctal),
+	Entry("handles missing prefix", "17", 0.0, calculator.ErrorInvalidOctal),
+)
+
+var _ = DescribeTable("ConvertScientific()",
+	func(in string, expRes float64, expErr error) {
+		result, err := calculator.ConvertScientific(in)
+		Expect(result).To(BeNumerically("==", expRes))
+		if expErr != nil {
+			Expect(err).To(Equal(expErr))
+		} else {
+			Expect(err).To(BeNil())
+		}
+	},
+	Entry("works with correct scientific", "1.5e-3", 0.0015, nil),
+	Entry("works with uppercase e", "6.02E23", 6.02e23, nil),
+	Entry("works with separators", "1_000e1_0", 1e13, nil),
+	Entry("handles invalid scientific", "1e", 0.0, calculator.ErrorInvalidScientific),
+	Entry("handles overflow", "1e400", 0.0, calculator.ErrorInvalidScientific),
+)
+
+var _ = DescribeTable("ConvertHexFloat()",
+	func(in string, expRes float64, expErr error) {
+		result, err := calculator.ConvertHexFloat(in)
+		Expect(result).To(BeNumerically("==", expRes))
+		if expErr != nil {
+			Expect(err).To(Equal(expErr))
+		} else {
+			Expect(err).To(BeNil())
+		}
+	},
+	Entry("works with correct hex float", "0x1.8p1", 3.0, nil),
+	Entry("works with negative exponent", "0x1p-2", 0.25, nil),
+	Entry("handles missing exponent", "0x1.8", 0.0, calculator.ErrorInvalidHexFloat),
+	Entry("handles invalid hex float", "0x1.Gp1", 0.0, calculator.ErrorInvalidHexFloat),
 )
 
 var _ = DescribeTable("CalculateOperator()",
@@ -119,9 +187,24 @@ var _ = DescribeTable("CalculateOperator()",
 	Entry("mod 3", 7.0, 2.0, parser.NMod, 1.0, nil),
 	Entry("mod 4", 7.0, 7.0, parser.NMod, 0.0, nil),
 	Entry("mod 4", 4.0, 2.0, parser.NMod, 0.0, nil),
+	Entry("mod negative dividend", -7.0, 3.0, parser.NMod, -1.0, nil),
+	Entry("mod negative divisor", 7.0, -3.0, parser.NMod, 1.0, nil),
+	Entry("mod negative numbers", -7.0, -3.0, parser.NMod, -1.0, nil),
+	Entry("mod decimals", 5.5, -2.0, parser.NMod, 1.5, nil),
+	Entry("mod large ratio", 1e300, 3.0, parser.NMod, math.Mod(1e300, 3), nil),
+	Entry("mod by zero", 7.0, 0.0, parser.NMod, 0.0, calculator.ErrorDivisionByZero),
+	Entry("mod negative by zero", -7.0, 0.0, parser.NMod, 0.0, calculator.ErrorDivisionByZero),
 	Entry("or", 1.0, 1.0, parser.NOr, 1.0, nil),
 	Entry("xor", 1.0, 1.0, parser.NXor, 0.0, nil),
 	Entry("and", 1.0, 0.0, parser.NAnd, 0.0, nil),
+	Entry("or of decimals", 1.5, 2.0, parser.NOr, 0.0, calculator.ErrorNotAnInteger),
+	Entry("xor of decimals", 1.0, 2.5, parser.NXor, 0.0, calculator.ErrorNotAnInteger),
+	Entry("and of decimals", 3.9, 1.0, parser.NAnd, 0.0, calculator.ErrorNotAnInteger),
+	Entry("shift left", 1.0, 4.0, parser.NShiftLeft, 16.0, nil),
+	Entry("shift right", 16.0, 3.0, parser.NShiftRight, 2.0, nil),
+	Entry("unsigned shift right", 16.0, 3.0, parser.NUnsignedShiftRight, 2.0, nil),
+	Entry("shift of decimal", 1.5, 1.0, parser.NShiftLeft, 0.0, calculator.ErrorNotAnInteger),
+	Entry("shift by negative count", 1.0, -1.0, parser.NShiftRight, 0.0, calculator.ErrorOutOfDomain),
 )
 
 var _ = DescribeTable("CalculateFunction()",
@@ -134,4 +217,166 @@ var _ = DescribeTable("CalculateFunction()",
 	Entry("sin", 9.0, parser.NFnSin, math.Sin(9.0)),
 	Entry("cos", 9.0, parser.NFnCos, math.Cos(9.0)),
 	Entry("tan", 9.0, parser.NFnTan, math.Tan(9.0)),
+	Entry("percent", 15.0, parser.NPercent, 0.15),
+	Entry("factorial", 5.0, parser.NFactorial, 120.0),
+	Entry("gamma", 5.0, parser.NFnGamma, 24.0),
+	Entry("isprime", 7.0, parser.NFnIsPrime, 1.0),
+	Entry("bitwise not", 5.0, parser.NBitNot, -6.0),
+)
+
+var _ = DescribeTable("CalculateBinaryFunction()",
+	func(left, right float64, nodeType parser.NodeType, expRes float64) {
+		result, err := calculator.CalculateBinaryFunction(left, right, nodeType)
+		Expect(result).To(BeNumerically("==", expRes))
+		Expect(err).To(BeNil())
+	},
+	Entry("binom", 5.0, 2.0, parser.NFnBinom, 10.0),
+	Entry("perm", 5.0, 2.0, parser.NFnPerm, 20.0),
+	Entry("gcd", 12.0, 18.0, parser.NFnGcd, 6.0),
+	Entry("lcm", 4.0, 6.0, parser.NFnLcm, 12.0),
+	Entry("mod", -7.0, 3.0, parser.NFnMod, 2.0),
 )
+
+var _ = Describe("Integer functions", func() {
+	type unary func(float64) (float64, error)
+	type binary func(float64, float64) (float64, error)
+
+	DescribeTable("functions with one argument",
+		func(fn unary, arg float64, expRes float64, expErr error) {
+			result, err := fn(arg)
+			Expect(result).To(BeNumerically("==", expRes))
+			if expErr != nil {
+				Expect(err).To(Equal(expErr))
+			} else {
+				Expect(err).To(BeNil())
+			}
+		},
+		Entry("factorial of 0", unary(calculator.Factorial), 0.0, 1.0, nil),
+		Entry("factorial of 10", unary(calculator.Factorial), 10.0, 3628800.0, nil),
+		Entry("largest factorial", unary(calculator.Factorial), 170.0, 7.257415615307994e+306, nil),
+		Entry("factorial overflow", unary(calculator.Factorial), 171.0, 0.0, calculator.ErrorOverflow),
+		Entry("factorial of negative number", unary(calculator.Factorial), -1.0, 0.0, calculator.ErrorOutOfDomain),
+		Entry("factorial of decimal", unary(calculator.Factorial), 1.5, 0.0, calculator.ErrorNotAnInteger),
+		Entry("factorial of NaN", unary(calculator.Factorial), math.NaN(), 0.0, calculator.ErrorNotAnInteger),
+		Entry("gamma of decimal", unary(calculator.Gamma), 0.5, math.Sqrt(math.Pi), nil),
+		Entry("gamma of negative decimal", unary(calculator.Gamma), -0.5, -2*math.Sqrt(math.Pi), nil),
+		Entry("gamma of zero", unary(calculator.Gamma), 0.0, 0.0, calculator.ErrorOutOfDomain),
+		Entry("gamma of negative integer", unary(calculator.Gamma), -2.0, 0.0, calculator.ErrorOutOfDomain),
+		Entry("gamma overflow", unary(calculator.Gamma), 172.0, 0.0, calculator.ErrorOverflow),
+		Entry("isprime of 2", unary(calculator.IsPrime), 2.0, 1.0, nil),
+		Entry("isprime of even number", unary(calculator.IsPrime), 4.0, 0.0, nil),
+		Entry("isprime of 1", unary(calculator.IsPrime), 1.0, 0.0, nil),
+		Entry("isprime of negative prime", unary(calculator.IsPrime), -7.0, 0.0, nil),
+		Entry("isprime of large prime", unary(calculator.IsPrime), 9007199254740881.0, 1.0, nil),
+		Entry("isprime of large composite", unary(calculator.IsPrime), 9007199254740883.0, 0.0, nil),
+		Entry("isprime of huge number", unary(calculator.IsPrime), 1e300, 0.0, nil),
+		Entry("isprime of decimal", unary(calculator.IsPrime), 7.5, 0.0, calculator.ErrorNotAnInteger),
+	)
+
+	DescribeTable("functions with two arguments",
+		func(fn binary, left, right float64, expRes float64, expErr error) {
+			result, err := fn(left, right)
+			Expect(result).To(BeNumerically("==", expRes))
+			if expErr != nil {
+				Expect(err).To(Equal(expErr))
+			} else {
+				Expect(err).To(BeNil())
+			}
+		},
+		Entry("binom", binary(calculator.Binom), 52.0, 5.0, 2598960.0, nil),
+		Entry("binom of 0", binary(calculator.Binom), 0.0, 0.0, 1.0, nil),
+		Entry("binom with k greater than n", binary(calculator.Binom), 2.0, 3.0, 0.0, nil),
+		Entry("binom stays exact", binary(calculator.Binom), 60.0, 30.0, 118264581564861424.0, nil),
+		Entry("binom of large n", binary(calculator.Binom), 1e15, 2.0, 1e15*(1e15-1)/2, nil),
+		Entry("binom overflow", binary(calculator.Binom), 2000.0, 1000.0, 0.0, calculator.ErrorOverflow),
+		Entry("binom of huge n", binary(calculator.Binom), 1e300, 1e299, 0.0, calculator.ErrorOverflow),
+		Entry("binom of negative number", binary(calculator.Binom), -5.0, 2.0, 0.0, calculator.ErrorOutOfDomain),
+		Entry("binom of decimal", binary(calculator.Binom), 5.0, 2.5, 0.0, calculator.ErrorNotAnInteger),
+		Entry("perm", binary(calculator.Perm), 10.0, 3.0, 720.0, nil),
+		Entry("perm of all elements", binary(calculator.Perm), 5.0, 5.0, 120.0, nil),
+		Entry("perm with k greater than n", binary(calculator.Perm), 2.0, 3.0, 0.0, nil),
+		Entry("perm overflow", binary(calculator.Perm), 1e12, 1000.0, 0.0, calculator.ErrorOverflow),
+		Entry("perm of nothing from large n", binary(calculator.Perm), 1e16, 0.0, 1.0, nil),
+		Entry("perm of one from large n", binary(calculator.Perm), 1e17, 1.0, 1e17, nil),
+		Entry("perm of two from large n", binary(calculator.Perm), 1e17, 2.0, 1e17*(1e17-1), nil),
+		Entry("perm of huge n", binary(calculator.Perm), 1e300, 1e299, 0.0, calculator.ErrorOverflow),
+		Entry("perm of negative number", binary(calculator.Perm), 5.0, -1.0, 0.0, calculator.ErrorOutOfDomain),
+		Entry("gcd with negative numbers", binary(calculator.Gcd), -12.0, 18.0, 6.0, nil),
+		Entry("gcd with zero", binary(calculator.Gcd), 0.0, -5.0, 5.0, nil),
+		Entry("gcd of zeros", binary(calculator.Gcd), 0.0, 0.0, 0.0, nil),
+		Entry("gcd of large numbers", binary(calculator.Gcd), 1e300, 1e299, 1e299, nil),
+		Entry("gcd of decimals", binary(calculator.Gcd), 1.5, 3.0, 0.0, calculator.ErrorNotAnInteger),
+		Entry("lcm with negative numbers", binary(calculator.Lcm), -4.0, 6.0, 12.0, nil),
+		Entry("lcm with zero", binary(calculator.Lcm), 0.0, 6.0, 0.0, nil),
+		Entry("lcm overflow", binary(calculator.Lcm), math.Ldexp(1, 1023), 3.0, 0.0, calculator.ErrorOverflow),
+		Entry("lcm of infinity", binary(calculator.Lcm), math.Inf(1), 2.0, 0.0, calculator.ErrorNotAnInteger),
+		Entry("floor mod", binary(calculator.FloorMod), 7.0, 3.0, 1.0, nil),
+		Entry("floor mod of negative dividend", binary(calculator.FloorMod), -7.0, 3.0, 2.0, nil),
+		Entry("floor mod of negative divisor", binary(calculator.FloorMod), 7.0, -3.0, -2.0, nil),
+		Entry("floor mod of negative numbers", binary(calculator.FloorMod), -7.0, -3.0, -1.0, nil),
+		Entry("floor mod without remainder", binary(calculator.FloorMod), -6.0, 3.0, 0.0, nil),
+		Entry("floor mod by zero", binary(calculator.FloorMod), 7.0, 0.0, 0.0, calculator.ErrorDivisionByZero),
+		Entry("floor mod of decimals", binary(calculator.FloorMod), 7.5, 2.0, 0.0, calculator.ErrorNotAnInteger),
+	)
+})
+
+var _ = Describe("Bitwise operators", func() {
+	type unary func(float64) (float64, error)
+	type binary func(float64, float64) (float64, error)
+
+	DescribeTable("operators with one operand",
+		func(fn unary, arg float64, expRes float64, expErr error) {
+			result, err := fn(arg)
+			Expect(result).To(BeNumerically("==", expRes))
+			if expErr != nil {
+				Expect(err).To(Equal(expErr))
+			} else {
+				Expect(err).To(BeNil())
+			}
+		},
+		Entry("not of 0", unary(calculator.BitNot), 0.0, -1.0, nil),
+		Entry("not of negative number", unary(calculator.BitNot), -256.0, 255.0, nil),
+		Entry("not of decimal", unary(calculator.BitNot), 0.5, 0.0, calculator.ErrorNotAnInteger),
+		Entry("not of infinity", unary(calculator.BitNot), math.Inf(-1), 0.0, calculator.ErrorNotAnInteger),
+		Entry("not of too large number", unary(calculator.BitNot), 1e19, 0.0, calculator.ErrorOutOfDomain),
+	)
+
+	DescribeTable("operators with two operands",
+		func(fn binary, left, right float64, expRes float64, expErr error) {
+			result, err := fn(left, right)
+			Expect(result).To(BeNumerically("==", expRes))
+			if expErr != nil {
+				Expect(err).To(Equal(expErr))
+			} else {
+				Expect(err).To(BeNil())
+			}
+		},
+		Entry("or", binary(calculator.Or), 240.0, 15.0, 255.0, nil),
+		Entry("or of negative numbers", binary(calculator.Or), -8.0, 3.0, -5.0, nil),
+		Entry("xor", binary(calculator.Xor), 255.0, 15.0, 240.0, nil),
+		Entry("and", binary(calculator.And), 255.0, -16.0, 240.0, nil),
+		Entry("and of large numbers", binary(calculator.And), 1e18, 1e18, 1e18, nil),
+		Entry("and of decimals", binary(calculator.And), 255.0, 15.5, 0.0, calculator.ErrorNotAnInteger),
+		Entry("or of NaN", binary(calculator.Or), math.NaN(), 1.0, 0.0, calculator.ErrorNotAnInteger),
+		Entry("xor of too large number", binary(calculator.Xor), math.Ldexp(1, 63), 1.0, 0.0, calculator.ErrorOutOfDomain),
+		Entry("xor of smallest int64", binary(calculator.Xor), math.Ldexp(-1, 63), 0.0, math.Ldexp(-1, 63), nil),
+		Entry("shift left", binary(calculator.ShiftLeft), 3.0, 4.0, 48.0, nil),
+		Entry("shift left of negative number", binary(calculator.ShiftLeft), -3.0, 2.0, -12.0, nil),
+		Entry("shift left by 0", binary(calculator.ShiftLeft), 5.0, 0.0, 5.0, nil),
+		Entry("shift left into sign bit", binary(calculator.ShiftLeft), -1.0, 63.0, math.Ldexp(-1, 63), nil),
+		Entry("shift left of 0 by large count", binary(calculator.ShiftLeft), 0.0, 100.0, 0.0, nil),
+		Entry("shift left overflow", binary(calculator.ShiftLeft), 1.0, 63.0, 0.0, calculator.ErrorOverflow),
+		Entry("shift left by large count", binary(calculator.ShiftLeft), 1.0, 64.0, 0.0, calculator.ErrorOverflow),
+		Entry("shift left by negative count", binary(calculator.ShiftLeft), 1.0, -1.0, 0.0, calculator.ErrorOutOfDomain),
+		Entry("shift left by decimal count", binary(calculator.ShiftLeft), 1.0, 0.5, 0.0, calculator.ErrorNotAnInteger),
+		Entry("shift right", binary(calculator.ShiftRight), 240.0, 4.0, 15.0, nil),
+		Entry("shift right of negative number", binary(calculator.ShiftRight), -8.0, 1.0, -4.0, nil),
+		Entry("shift right by large count", binary(calculator.ShiftRight), -8.0, 100.0, -1.0, nil),
+		Entry("shift right of decimal", binary(calculator.ShiftRight), 8.5, 1.0, 0.0, calculator.ErrorNotAnInteger),
+		Entry("unsigned shift right", binary(calculator.UnsignedShiftRight), 240.0, 4.0, 15.0, nil),
+		Entry("unsigned shift right of negative number", binary(calculator.UnsignedShiftRight), -1.0, 60.0, 15.0, nil),
+		Entry("unsigned shift right by 0", binary(calculator.UnsignedShiftRight), -1.0, 0.0, math.Ldexp(1, 64), nil),
+		Entry("unsigned shift right by large count", binary(calculator.UnsignedShiftRight), -1.0, 64.0, 0.0, nil),
+		Entry("unsigned shift right by negative count", binary(calculator.UnsignedShiftRight), 1.0, -2.0, 0.0, calculator.ErrorOutOfDomain),
+	)
+})
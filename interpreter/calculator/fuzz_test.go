@@ -0,0 +1,126 @@
+package calculator_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/parser"
+)
+
+// scaled returns x * 2^1074 as an integer. This is exact for all finite
+// float64 values, because 2^-1074 is the smallest one.
+func scaled(x float64) *big.Int {
+	f := new(big.Float).SetFloat64(x)
+	f.SetMantExp(f, 1074)
+	i, _ := f.Int(nil)
+
+	return i
+}
+
+func FuzzMod(f *testing.F) {
+	f.Add(7.0, 3.0)
+	f.Add(-7.0, 3.0)
+	f.Add(7.0, -3.0)
+	f.Add(-7.0, -3.0)
+	f.Add(5.5, 2.0)
+	f.Add(-0.3, 0.1)
+	f.Add(1e300, 7.0)
+	f.Add(3.0, 1e300)
+	f.Add(5e-324, 3e-324)
+	f.Add(1.0, 0.0)
+	f.Add(math.Inf(1), 2.0)
+	f.Add(2.0, math.Inf(-1))
+
+	f.Fuzz(func(t *testing.T, left, right float64) {
+		result, err := calculator.CalculateOperator(left, right, parser.NMod)
+		if right == 0 {
+			if err != calculator.ErrorDivisionByZero {
+				t.Errorf("%v %% %v: got error %v, expected division by zero", left, right, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("%v %% %v: unexpected error %v", left, right, err)
+		}
+
+		switch {
+		case math.IsNaN(left) || math.IsNaN(right) || math.IsInf(left, 0):
+			if !math.IsNaN(result) {
+				t.Errorf("%v %% %v: got %v, expected NaN", left, right, result)
+			}
+			return
+		case math.IsInf(right, 0):
+			if result != left {
+				t.Errorf("%v %% %v: got %v, expected the dividend", left, right, result)
+			}
+			return
+		}
+
+		if math.Abs(result) >= math.Abs(right) {
+			t.Errorf("%v %% %v: |%v| is not smaller than the divisor", left, right, result)
+		}
+		if result != 0 && math.Signbit(result) != math.Signbit(left) {
+			t.Errorf("%v %% %v: %v doesn't have the sign of the dividend", left, right, result)
+		}
+
+		// big.Int.Rem truncates like Go's % operator. Scaling both operands
+		// by the same power of two scales the remainder, too.
+		expected := new(big.Int).Rem(scaled(left), scaled(right))
+		if scaled(result).Cmp(expected) != 0 {
+			t.Errorf("%v %% %v: got %v, expected %v * 2^-1074", left, right, result, expected)
+		}
+	})
+}
+
+func FuzzModIntegers(f *testing.F) {
+	f.Add(int64(7), int64(3))
+	f.Add(int64(-7), int64(3))
+	f.Add(int64(7), int64(-3))
+	f.Add(int64(-7), int64(-3))
+	f.Add(int64(-6), int64(3))
+	f.Add(int64(1)<<53, int64(-1))
+	f.Add(-int64(1)<<53+1, int64(1)<<52)
+	f.Add(int64(1), int64(0))
+
+	f.Fuzz(func(t *testing.T, left, right int64) {
+		// Larger integers can't be represented exactly as float64.
+		if left > 1<<53 || left < -1<<53 || right > 1<<53 || right < -1<<53 {
+			return
+		}
+
+		truncated, err := calculator.CalculateOperator(float64(left), float64(right), parser.NMod)
+		floored, floorErr := calculator.FloorMod(float64(left), float64(right))
+		if right == 0 {
+			if err != calculator.ErrorDivisionByZero || floorErr != calculator.ErrorDivisionByZero {
+				t.Errorf("%d mod 0: got errors %v and %v, expected division by zero", left, err, floorErr)
+			}
+			return
+		}
+		if err != nil || floorErr != nil {
+			t.Fatalf("%d mod %d: unexpected errors %v and %v", left, right, err, floorErr)
+		}
+
+		a, b := big.NewInt(left), big.NewInt(right)
+		if expected := new(big.Int).Rem(a, b); truncated != float64(expected.Int64()) {
+			t.Errorf("%d %% %d: got %v, expected %v", left, right, truncated, expected)
+		}
+
+		// The floored remainder r is the only value with a = q*b + r for an
+		// integer q, |r| < |b| and the sign of b.
+		r := big.NewInt(int64(floored))
+		if float64(r.Int64()) != floored {
+			t.Fatalf("mod(%d, %d): %v is not an integer", left, right, floored)
+		}
+		if new(big.Int).Rem(new(big.Int).Sub(a, r), b).Sign() != 0 {
+			t.Errorf("mod(%d, %d): %d - %v is not a multiple of the divisor", left, right, left, floored)
+		}
+		if r.CmpAbs(b) >= 0 {
+			t.Errorf("mod(%d, %d): |%v| is not smaller than the divisor", left, right, floored)
+		}
+		if r.Sign() != 0 && r.Sign() != b.Sign() {
+			t.Errorf("mod(%d, %d): %v doesn't have the sign of the divisor", left, right, floored)
+		}
+	})
+}
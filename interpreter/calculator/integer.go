@@ -0,0 +1,207 @@
+package calculator
+
+import (
+	"math"
+	"math/big"
+)
+
+// maxFactorial is the largest number, whose factorial fits into a float64.
+const maxFactorial = 170
+
+// Factorial returns n!. Returns an error if n is not a non-negative integer or
+// if the result overflows.
+func Factorial(n float64) (float64, error) {
+	if !isInteger(n) {
+		return 0, ErrorNotAnInteger
+	}
+	if n < 0 {
+		return 0, ErrorOutOfDomain
+	}
+	if n > maxFactorial {
+		return 0, ErrorOverflow
+	}
+
+	result := 1.0
+	for i := 2.0; i <= n; i++ {
+		result *= i
+	}
+
+	return result, nil
+}
+
+// Gamma returns the gamma function of x. Returns an error if x is zero or a
+// negative integer or if the result overflows.
+func Gamma(x float64) (float64, error) {
+	if x <= 0 && isInteger(x) {
+		return 0, ErrorOutOfDomain
+	}
+
+	return checkOverflow(math.Gamma(x))
+}
+
+// Binom returns the binomial coefficient "n choose k". Returns an error if n or
+// k are not non-negative integers or if the result overflows. The result is 0,
+// if k is greater than n.
+func Binom(n, k float64) (float64, error) {
+	if err := checkNatural(n, k); err != nil {
+		return 0, err
+	}
+	if k > n {
+		return 0, nil
+	}
+
+	k = math.Min(k, n-k)
+
+	// The intermediate results are binomial coefficients themselves, so they
+	// stay exact as long as they fit into the mantissa. They at least double
+	// in each step, which ends the loop soon after an overflow.
+	result := 1.0
+	for i := 1.0; i <= k; i++ {
+		result = result * (n - k + i) / i
+		if math.IsInf(result, 0) {
+			return 0, ErrorOverflow
+		}
+	}
+
+	return result, nil
+}
+
+// Perm returns the number of ordered arrangements of k out of n elements.
+// Returns an error if n or k are not non-negative integers or if the result
+// overflows. The result is 0, if k is greater than n.
+func Perm(n, k float64) (float64, error) {
+	if err := checkNatural(n, k); err != nil {
+		return 0, err
+	}
+	if k > n {
+		return 0, nil
+	}
+	if k == 0 {
+		return 1, nil
+	}
+
+	// Counting up from n - k + 1 would get stuck above 2^53, where adding 1
+	// doesn't change a float64 anymore.
+	result := 1.0
+	for j := 0.0; j < k; j++ {
+		result *= n - j
+		if math.IsInf(result, 0) {
+			return 0, ErrorOverflow
+		}
+	}
+
+	return result, nil
+}
+
+// Gcd returns the greatest common divisor of a and b. The result is always
+// non-negative. Returns an error if a or b are not integers.
+func Gcd(a, b float64) (float64, error) {
+	if !isInteger(a) || !isInteger(b) {
+		return 0, ErrorNotAnInteger
+	}
+
+	return gcd(math.Abs(a), math.Abs(b)), nil
+}
+
+// Lcm returns the least common multiple of a and b. The result is always
+// non-negative. Returns an error if a or b are not integers or if the result
+// overflows.
+func Lcm(a, b float64) (float64, error) {
+	if !isInteger(a) || !isInteger(b) {
+		return 0, ErrorNotAnInteger
+	}
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+
+	a, b = math.Abs(a), math.Abs(b)
+
+	return checkOverflow(a / gcd(a, b) * b)
+}
+
+// IsPrime returns 1 if n is a prime number and 0 otherwise. Returns an error if
+// n is not an integer.
+func IsPrime(n float64) (float64, error) {
+	if !isInteger(n) {
+		return 0, ErrorNotAnInteger
+	}
+	if n < 2 {
+		return 0, nil
+	}
+
+	// All float64 values from 2^53 on are even integers, so odd numbers are
+	// smaller and fit into an int64.
+	if math.Mod(n, 2) == 0 {
+		return boolToFloat(n == 2), nil
+	}
+
+	return boolToFloat(big.NewInt(int64(n)).ProbablyPrime(0)), nil
+}
+
+// FloorMod returns the remainder of the floored division of a by b. The result
+// has the sign of b, so FloorMod(-7, 3) is 2. Returns an error if a or b are not
+// integers or if b is zero. See Mod for the truncated division.
+func FloorMod(a, b float64) (float64, error) {
+	if !isInteger(a) || !isInteger(b) {
+		return 0, ErrorNotAnInteger
+	}
+	if b == 0 {
+		return 0, ErrorDivisionByZero
+	}
+
+	result := Mod(a, b)
+	if result != 0 && (result < 0) != (b < 0) {
+		result += b
+	}
+
+	return result, nil
+}
+
+// gcd returns the greatest common divisor of the non-negative integers a and
+// b. math.Mod is exact, so the euclidean algorithm works for all float64
+// integers.
+func gcd(a, b float64) float64 {
+	for b != 0 {
+		a, b = b, math.Mod(a, b)
+	}
+
+	return a
+}
+
+// isInteger returns true if x is a finite integer.
+func isInteger(x float64) bool {
+	return x == math.Trunc(x) && !math.IsInf(x, 0)
+}
+
+// checkNatural returns an error, if one of the given numbers isn't a
+// non-negative integer.
+func checkNatural(numbers ...float64) error {
+	for _, x := range numbers {
+		if !isInteger(x) {
+			return ErrorNotAnInteger
+		}
+		if x < 0 {
+			return ErrorOutOfDomain
+		}
+	}
+
+	return nil
+}
+
+// checkOverflow returns an error, if x is infinite.
+func checkOverflow(x float64) (float64, error) {
+	if math.IsInf(x, 0) {
+		return 0, ErrorOverflow
+	}
+
+	return x, nil
+}
+
+// boolToFloat converts true to 1 and false to 0.
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,317 @@
+// Package closure contains a compiler, that translates an ast into nested Go
+// closures. Literals get converted and variables get resolved at compile time,
+// so evaluating a compiled function neither parses strings nor dispatches
+// through the node interface.
+package closure
+
+import (
+	"errors"
+	"math"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/parser"
+)
+
+// Errors, that can occur during compiling or evaluating a function
+var (
+	ErrorMissingLeftChild        = errors.New("Error: Missing left child of node")
+	ErrorMissingRightChild       = errors.New("Error: Missing right child of node")
+	ErrorMissingFunctionArgument = errors.New("Error: Missing function argument")
+	ErrorInvalidNodeType         = errors.New("Error: Invalid node type")
+	ErrorValuesLength            = errors.New("Error: Number of values doesn't match the number of variables")
+)
+
+// evalFunc evaluates a compiled node with the values of all variables.
+type evalFunc func(values []float64) (float64, error)
+
+// Function holds a compiled expression. A function never gets modified after
+// its creation, so it can be evaluated concurrently.
+type Function struct {
+	eval evalFunc
+	vars []string
+}
+
+// compiler holds the state of the compiler.
+type compiler struct {
+	vars  []string
+	index map[string]int
+}
+
+// Compile compiles an ast into a function. Optimized asts are supported as
+// well.
+func Compile(ast parser.IAST) (*Function, error) {
+	if parser.IsEmpty(ast) {
+		return &Function{eval: constant(0)}, nil
+	}
+
+	c := &compiler{index: make(map[string]int)}
+	eval, err := c.compileNode(ast.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Function{eval: eval, vars: c.vars}, nil
+}
+
+// CompileString parses a string and compiles the resulting ast into a
+// function.
+func CompileString(str string) (*Function, []error) {
+	if len(str) == 0 {
+		return &Function{eval: constant(0)}, nil
+	}
+
+	ast, errors := parser.Parse(str)
+	if errors != nil {
+		return nil, errors
+	}
+
+	f, err := Compile(&ast)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return f, nil
+}
+
+// Run compiles and evaluates a given string.
+// Returns errors if lexing, parsing, compiling or evaluating failed.
+func Run(str string) (float64, []error) {
+	f, errors := CompileString(str)
+	if errors != nil {
+		return 0, errors
+	}
+
+	result, err := f.Eval(nil)
+	if err != nil {
+		return 0, []error{err}
+	}
+
+	return result, nil
+}
+
+// Vars returns the names of all variables in the order, that is expected by
+// EvalValues.
+func (f *Function) Vars() []string {
+	return f.vars
+}
+
+// Eval evaluates the function with the variables of the given environment.
+// Returns interpreter.ErrorVariableNotDefined, if a variable is missing.
+func (f *Function) Eval(env interpreter.Env) (float64, error) {
+	values := make([]float64, len(f.vars))
+	for i, name := range f.vars {
+		value, ok := env[name]
+		if !ok {
+			return 0, interpreter.ErrorVariableNotDefined
+		}
+		values[i] = value
+	}
+
+	return f.eval(values)
+}
+
+// EvalValues evaluates the function with the values of all variables in the
+// order of Vars(). This avoids the lookup of the variables.
+func (f *Function) EvalValues(values []float64) (float64, error) {
+	if len(values) != len(f.vars) {
+		return 0, ErrorValuesLength
+	}
+
+	return f.eval(values)
+}
+
+// compileNode recursively compiles a node.
+func (c *compiler) compileNode(n parser.INode) (evalFunc, error) {
+	if parser.IsLiteral(n) {
+		return c.compileLiteral(n)
+	}
+
+	if parser.IsOperator(n) {
+		return c.compileOperator(n)
+	}
+
+	if parser.IsBinaryFunction(n) {
+		return c.compileBinaryFunction(n)
+	}
+
+	if parser.IsFunction(n) {
+		return c.compileFunction(n)
+	}
+
+	return nil, ErrorInvalidNodeType
+}
+
+// compileLiteral compiles a number or variable node.
+func (c *compiler) compileLiteral(n parser.INode) (evalFunc, error) {
+	if n.GetType() == parser.NVar {
+		index, ok := c.index[n.GetValue()]
+		if !ok {
+			index = len(c.vars)
+			c.index[n.GetValue()] = index
+			c.vars = append(c.vars, n.GetValue())
+		}
+
+		return func(values []float64) (float64, error) {
+			return values[index], nil
+		}, nil
+	}
+
+	value, err := n.Calculate(convertLiteral)
+	if err != nil {
+		return nil, err
+	}
+
+	return constant(value), nil
+}
+
+// compileOperator compiles an operator node and both of its child nodes.
+func (c *compiler) compileOperator(n parser.INode) (evalFunc, error) {
+	if n.Left() == nil {
+		return nil, ErrorMissingLeftChild
+	}
+	if n.Right() == nil {
+		return nil, ErrorMissingRightChild
+	}
+
+	left, err := c.compileNode(n.Left())
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.compileNode(n.Right())
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.GetType() {
+	case parser.NAdd:
+		return func(values []float64) (float64, error) {
+			l, r, err := evalChilds(left, right, values)
+			return l + r, err
+		}, nil
+	case parser.NSub:
+		return func(values []float64) (float64, error) {
+			l, r, err := evalChilds(left, right, values)
+			return l - r, err
+		}, nil
+	case parser.NMult:
+		return func(values []float64) (float64, error) {
+			l, r, err := evalChilds(left, right, values)
+			return l * r, err
+		}, nil
+	case parser.NDiv:
+		return func(values []float64) (float64, error) {
+			l, r, err := evalChilds(left, right, values)
+			if err != nil {
+				return 0, err
+			}
+			if r == 0 {
+				return 0, calculator.ErrorDivisionByZero
+			}
+			return l / r, nil
+		}, nil
+	}
+
+	nodeType := n.GetType()
+	return func(values []float64) (float64, error) {
+		l, r, err := evalChilds(left, right, values)
+		if err != nil {
+			return 0, err
+		}
+		return calculator.CalculateOperator(l, r, nodeType)
+	}, nil
+}
+
+// compileFunction compiles a function node and its argument.
+func (c *compiler) compileFunction(n parser.INode) (evalFunc, error) {
+	if n.Left() == nil {
+		return nil, ErrorMissingFunctionArgument
+	}
+
+	arg, err := c.compileNode(n.Left())
+	if err != nil {
+		return nil, err
+	}
+
+	var fn func(float64) float64
+	switch n.GetType() {
+	case parser.NFnSqrt:
+		fn = math.Sqrt
+	case parser.NFnSin:
+		fn = math.Sin
+	case parser.NFnCos:
+		fn = math.Cos
+	case parser.NFnTan:
+		fn = math.Tan
+	default:
+		nodeType := n.GetType()
+		return func(values []float64) (float64, error) {
+			a, err := arg(values)
+			if err != nil {
+				return 0, err
+			}
+			return calculator.CalculateFunction(a, nodeType)
+		}, nil
+	}
+
+	return func(values []float64) (float64, error) {
+		a, err := arg(values)
+		if err != nil {
+			return 0, err
+		}
+		return fn(a), nil
+	}, nil
+}
+
+// compileBinaryFunction compiles a function node and both of its arguments.
+func (c *compiler) compileBinaryFunction(n parser.INode) (evalFunc, error) {
+	if n.Left() == nil || n.Right() == nil {
+		return nil, ErrorMissingFunctionArgument
+	}
+
+	left, err := c.compileNode(n.Left())
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.compileNode(n.Right())
+	if err != nil {
+		return nil, err
+	}
+
+	nodeType := n.GetType()
+	return func(values []float64) (float64, error) {
+		l, r, err := evalChilds(left, right, values)
+		if err != nil {
+			return 0, err
+		}
+		return calculator.CalculateBinaryFunction(l, r, nodeType)
+	}, nil
+}
+
+// evalChilds evaluates both child nodes of an operator or a function with two
+// arguments.
+func evalChilds(left, right evalFunc, values []float64) (float64, float64, error) {
+	l, err := left(values)
+	if err != nil {
+		return 0, 0, err
+	}
+	r, err := right(values)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return l, r, nil
+}
+
+// constant returns a function, that always returns value.
+func constant(value float64) evalFunc {
+	return func(values []float64) (float64, error) {
+		return value, nil
+	}
+}
+
+// convertLiteral is the calculation visitor used to convert literals at
+// compile time. Already optimized nodes don't call the visitor.
+func convertLiteral(n parser.INode) (float64, error) {
+	return calculator.ConvertLiteral(n.GetValue(), n.GetType())
+}
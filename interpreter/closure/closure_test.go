@@ -0,0 +1,126 @@
+package closure_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/interpreter/closure"
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/parser"
+)
+
+func TestClosure(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Closure Suite")
+}
+
+var env = interpreter.Env{"a": 3, "b": 0.5, "c": 0}
+
+var _ = Describe("Closure", func() {
+	DescribeTable("returns the same result as the interpreter",
+		func(str string) {
+			e, errs := interpreter.NewExpression(str)
+			Expect(errs).To(BeNil())
+			expected, expErr := e.Eval(env)
+
+			f, errs := closure.CompileString(str)
+			Expect(errs).To(BeNil())
+			result, err := f.Eval(env)
+			Expect(result).To(Equal(expected))
+			if expErr != nil {
+				Expect(err).To(Equal(expErr))
+			} else {
+				Expect(err).To(BeNil())
+			}
+		},
+		Entry("empty", ""),
+		Entry("integer", "1"),
+		Entry("literals", "1.5 + 0b101 - 0x1F * 2^3"),
+		Entry("operators", "1 + 2 - 3 * 4 / 5"),
+		Entry("modulo and bitwise operators", "7 % 3 + (6 | 1) - (5 ^ 3) * (7 & 2)"),
+		Entry("shifts and bitwise not", "(a << 2 | ~a) >> 1 ^ a >>> 1"),
+		Entry("bitwise error", "a & b"),
+		Entry("brackets", "(1 + 2) * (3 - 4) / (5 + 6)"),
+		Entry("functions", "sqrt(4) + sin(1) - cos(2) * tan(3)"),
+		Entry("integer functions", "a! + binom(a + 3, 2) - lcm(a, 4) * mod(0 - a, 2) + gamma(b)"),
+		Entry("function error", "perm(a, 1) + gcd(a, b)"),
+		Entry("variables", "a * (b + 1) - a / b"),
+		Entry("division by zero", "a / c"),
+		Entry("modulo by zero", "a % c"),
+		Entry("nested division by zero", "1 + sqrt(a / (b - 0.5))"),
+		Entry("division by zero on the left", "a / c + 1 / 0"),
+	)
+
+	It("returns the variables in order of their first occurrence", func() {
+		f, errs := closure.CompileString("b + a * b + c")
+		Expect(errs).To(BeNil())
+		Expect(f.Vars()).To(Equal([]string{"b", "a", "c"}))
+
+		Expect(f.EvalValues([]float64{2, 3, 4})).To(Equal(12.0))
+	})
+
+	It("returns an error, if a variable is missing", func() {
+		f, errs := closure.CompileString("a + d")
+		Expect(errs).To(BeNil())
+
+		_, err := f.Eval(env)
+		Expect(err).To(Equal(interpreter.ErrorVariableNotDefined))
+	})
+
+	It("returns an error, if the number of values doesn't match", func() {
+		f, errs := closure.CompileString("a + b")
+		Expect(errs).To(BeNil())
+
+		_, err := f.EvalValues([]float64{1})
+		Expect(err).To(Equal(closure.ErrorValuesLength))
+	})
+
+	It("compiles optimized asts", func() {
+		ast, errs := parser.Parse("(1 + 2) * a + 1 / 4")
+		Expect(errs).To(BeNil())
+		oast, err := optimizer.Optimize(&ast)
+		Expect(err).To(BeNil())
+
+		f, err := closure.Compile(oast)
+		Expect(err).To(BeNil())
+		Expect(f.Eval(env)).To(Equal(9.25))
+	})
+
+	DescribeTable("fails to compile invalid asts",
+		func(ast parser.IAST, expErr error) {
+			f, err := closure.Compile(ast)
+			Expect(f).To(BeNil())
+			Expect(err).To(Equal(expErr))
+		},
+		Entry("missing left child", &parser.AST{Node: &parser.Node{
+			Type: parser.NAdd, RightChild: &parser.Node{Type: parser.NInt, Value: "1"},
+		}}, closure.ErrorMissingLeftChild),
+		Entry("missing right child", &parser.AST{Node: &parser.Node{
+			Type: parser.NAdd, LeftChild: &parser.Node{Type: parser.NInt, Value: "1"},
+		}}, closure.ErrorMissingRightChild),
+		Entry("missing function argument", &parser.AST{Node: &parser.Node{
+			Type: parser.NFnSqrt,
+		}}, closure.ErrorMissingFunctionArgument),
+		Entry("invalid node type", &parser.AST{Node: &parser.Node{
+			Type: parser.NError,
+		}}, closure.ErrorInvalidNodeType),
+		Entry("invalid literal", &parser.AST{Node: &parser.Node{
+			Type: parser.NInt, Value: "a",
+		}}, calculator.ErrorInvalidInteger),
+	)
+
+	It("runs a string", func() {
+		Expect(closure.Run("(1 + 2) * 3")).To(Equal(9.0))
+
+		_, errs := closure.Run("1 + $")
+		Expect(errs).To(Equal([]error{parser.ErrorExpectedNumberOrVariable}))
+
+		_, errs = closure.Run("1 / 0")
+		Expect(errs).To(Equal([]error{calculator.ErrorDivisionByZero}))
+	})
+})
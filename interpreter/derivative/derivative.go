@@ -0,0 +1,224 @@
+// Package derivative contains the symbolic differentiation of an ast.
+package derivative
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/interpreter/simplifier"
+	"github.com/relnod/calcgo/parser"
+)
+
+// Errors, that can occur during differentiation
+var (
+	ErrorMissingLeftChild        = errors.New("Error: Missing left child of node")
+	ErrorMissingRightChild       = errors.New("Error: Missing right child of node")
+	ErrorMissingFunctionArgument = errors.New("Error: Missing function argument")
+	ErrorInvalidNodeType         = errors.New("Error: Invalid node type")
+	ErrorNotDifferentiable       = errors.New("Error: Operator is not differentiable")
+	ErrorUnknownDerivative       = errors.New("Error: Derivative of function is unknown")
+)
+
+// functionDerivatives maps a function to its derivative f'(arg). The chain rule
+// gets applied by Derive.
+var functionDerivatives = map[parser.NodeType]func(arg parser.INode) parser.INode{
+	// sqrt(u)' = 1 / (2 * sqrt(u))
+	parser.NFnSqrt: func(arg parser.INode) parser.INode {
+		return div(newNumber(1), mult(newNumber(2), function(parser.NFnSqrt, arg)))
+	},
+	// sin(u)' = cos(u)
+	parser.NFnSin: func(arg parser.INode) parser.INode {
+		return function(parser.NFnCos, arg)
+	},
+	// cos(u)' = -1 * sin(u)
+	parser.NFnCos: func(arg parser.INode) parser.INode {
+		return mult(newNumber(-1), function(parser.NFnSin, arg))
+	},
+	// tan(u)' = 1 / (cos(u) * cos(u))
+	parser.NFnTan: func(arg parser.INode) parser.INode {
+		return div(newNumber(1), mult(function(parser.NFnCos, arg), function(parser.NFnCos, arg)))
+	},
+	// u%' = 1 / 100
+	parser.NPercent: func(arg parser.INode) parser.INode {
+		return div(newNumber(1), newNumber(100))
+	},
+}
+
+// Derive returns a new ast, that holds the derivative of the given ast with
+// respect to variable. The zeros and ones, that the differentiation rules
+// produce, get removed right away and the result gets simplified by the
+// simplifier. The given ast doesn't get modified.
+func Derive(ast parser.IAST, variable string) (*parser.AST, error) {
+	if parser.IsEmpty(ast) {
+		return &parser.AST{}, nil
+	}
+
+	d, err := deriveNode(ast.Root(), variable)
+	if err != nil {
+		return nil, err
+	}
+
+	return simplifier.Simplify(&parser.AST{Node: simplify(d).(*parser.Node)})
+}
+
+// DeriveString parses a string and returns the derivative of the resulting
+// ast with respect to variable.
+func DeriveString(str string, variable string) (*parser.AST, []error) {
+	ast, errors := parser.Parse(str)
+	if errors != nil {
+		return nil, errors
+	}
+
+	d, err := Derive(&ast, variable)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return d, nil
+}
+
+// deriveNode recursively derives a node.
+func deriveNode(n parser.INode, variable string) (parser.INode, error) {
+	if parser.IsLiteral(n) {
+		if n.GetType() == parser.NVar && n.GetValue() == variable {
+			return newNumber(1), nil
+		}
+
+		return newNumber(0), nil
+	}
+
+	if parser.IsOperator(n) {
+		return deriveOperator(n, variable)
+	}
+
+	if parser.IsFunction(n) {
+		return deriveFunction(n, variable)
+	}
+
+	return nil, ErrorInvalidNodeType
+}
+
+// deriveOperator derives an operator node using the sum, product and quotient
+// rules.
+func deriveOperator(n parser.INode, variable string) (parser.INode, error) {
+	if n.Left() == nil {
+		return nil, ErrorMissingLeftChild
+	}
+	if n.Right() == nil {
+		return nil, ErrorMissingRightChild
+	}
+
+	u, v := copyTree(n.Left()), copyTree(n.Right())
+
+	du, err := deriveNode(n.Left(), variable)
+	if err != nil {
+		return nil, err
+	}
+	dv, err := deriveNode(n.Right(), variable)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.GetType() {
+	case parser.NAdd:
+		// (u + v)' = u' + v'
+		return add(du, dv), nil
+	case parser.NSub:
+		// (u - v)' = u' - v'
+		return sub(du, dv), nil
+	case parser.NMult:
+		// (u * v)' = u' * v + u * v'
+		return add(mult(du, v), mult(u, dv)), nil
+	case parser.NDiv:
+		// (u / v)' = (u' * v - u * v') / (v * v)
+		return div(sub(mult(du, v), mult(u, dv)), mult(v, copyTree(v))), nil
+	}
+
+	return nil, ErrorNotDifferentiable
+}
+
+// deriveFunction derives a function node using the chain rule.
+func deriveFunction(n parser.INode, variable string) (parser.INode, error) {
+	if n.Left() == nil {
+		return nil, ErrorMissingFunctionArgument
+	}
+
+	derivative, ok := functionDerivatives[n.GetType()]
+	if !ok {
+		return nil, ErrorUnknownDerivative
+	}
+
+	du, err := deriveNode(n.Left(), variable)
+	if err != nil {
+		return nil, err
+	}
+
+	// f(u)' = f'(u) * u'
+	return mult(derivative(copyTree(n.Left())), du), nil
+}
+
+// copyTree returns a deep copy of a tree, that only consists of parser nodes.
+// Already optimized nodes get converted to number nodes.
+func copyTree(n parser.INode) parser.INode {
+	if n == nil {
+		return nil
+	}
+
+	node, ok := n.(*parser.Node)
+	if !ok {
+		value, _ := n.Calculate(nil)
+		return newNumber(value)
+	}
+	if node == nil {
+		return nil
+	}
+
+	return &parser.Node{
+		Type:       node.Type,
+		Value:      node.Value,
+		LeftChild:  copyTree(node.LeftChild),
+		RightChild: copyTree(node.RightChild),
+	}
+}
+
+// newNumber returns a new integer or decimal node holding value.
+func newNumber(value float64) *parser.Node {
+	if value == math.Trunc(value) && math.Abs(value) < 1e15 {
+		return &parser.Node{Type: parser.NInt, Value: strconv.FormatInt(int64(value), 10)}
+	}
+
+	return &parser.Node{Type: parser.NDec, Value: strconv.FormatFloat(value, 'f', -1, 64)}
+}
+
+// operator returns a new operator node.
+func operator(t parser.NodeType, l, r parser.INode) *parser.Node {
+	return &parser.Node{Type: t, LeftChild: l, RightChild: r}
+}
+
+// Helpers to create operator nodes.
+func add(l, r parser.INode) *parser.Node  { return operator(parser.NAdd, l, r) }
+func sub(l, r parser.INode) *parser.Node  { return operator(parser.NSub, l, r) }
+func mult(l, r parser.INode) *parser.Node { return operator(parser.NMult, l, r) }
+func div(l, r parser.INode) *parser.Node  { return operator(parser.NDiv, l, r) }
+
+// function returns a new function node.
+func function(t parser.NodeType, arg parser.INode) *parser.Node {
+	return &parser.Node{Type: t, LeftChild: arg}
+}
+
+// numberValue returns the value of a number node. The second return value is
+// false, if n is not a number.
+func numberValue(n parser.INode) (float64, bool) {
+	if !parser.IsLiteral(n) || n.GetType() == parser.NVar {
+		return 0, false
+	}
+
+	value, err := calculator.ConvertLiteral(n.GetValue(), n.GetType())
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
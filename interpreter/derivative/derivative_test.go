@@ -0,0 +1,150 @@
+package derivative_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/derivative"
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/parser"
+)
+
+func TestDerivative(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Derivative Suite")
+}
+
+// eval evaluates an ast with the given value for x and y = 2.
+func eval(ast parser.IAST, x float64) float64 {
+	result, err := interpreter.NewExpressionFromAST(ast).Eval(interpreter.Env{"x": x, "y": 2})
+	Expect(err).To(BeNil())
+	return result
+}
+
+var _ = Describe("Derive()", func() {
+	DescribeTable("matches the numerical derivative",
+		func(in string) {
+			ast, errs := parser.Parse(in)
+			Expect(errs).To(BeNil())
+
+			d, err := derivative.Derive(&ast, "x")
+			Expect(err).To(BeNil())
+
+			const h = 1e-6
+			for _, x := range []float64{0.3, 0.7, 1.5, 2.2} {
+				numerical := (eval(&ast, x+h) - eval(&ast, x-h)) / (2 * h)
+				Ω(eval(d, x)).Should(BeNumerically("~", numerical, 1e-4))
+			}
+		},
+		Entry("constant", "3"),
+		Entry("variable", "x"),
+		Entry("other variable", "y"),
+		Entry("addition", "x + y + 1"),
+		Entry("subtraction", "1 - x - x"),
+		Entry("product", "x * x * y"),
+		Entry("quotient", "1 / x"),
+		Entry("quotient of variables", "(x + 1) / (x * x)"),
+		Entry("sqrt", "sqrt(x)"),
+		Entry("sin", "sin(x * y)"),
+		Entry("cos", "cos(x * x)"),
+		Entry("tan", "tan(x / 2)"),
+		Entry("percent", "x% * y"),
+		Entry("nested functions", "sin(cos(sqrt(x)))"),
+		Entry("mixed", "x * sin(x) + sqrt(x * x + 1) / (x + 2)"),
+	)
+
+	DescribeTable("simplifies the result",
+		func(in string, expected *parser.AST) {
+			d, errs := derivative.DeriveString(in, "x")
+			Expect(errs).To(BeNil())
+			Expect(d).To(Equal(expected))
+		},
+		Entry("constant", "2", &parser.AST{Node: &parser.Node{Type: parser.NInt, Value: "0"}}),
+		Entry("variable", "x", &parser.AST{Node: &parser.Node{Type: parser.NInt, Value: "1"}}),
+		Entry("linear", "3 * x + 1", &parser.AST{Node: &parser.Node{Type: parser.NInt, Value: "3"}}),
+		Entry("square", "x * x", &parser.AST{Node: &parser.Node{
+			Type:       parser.NMult,
+			LeftChild:  &parser.Node{Type: parser.NInt, Value: "2"},
+			RightChild: &parser.Node{Type: parser.NVar, Value: "x"},
+		}}),
+		Entry("sin", "sin(x)", &parser.AST{Node: &parser.Node{
+			Type:      parser.NFnCos,
+			LeftChild: &parser.Node{Type: parser.NVar, Value: "x"},
+		}}),
+		Entry("decimal result", "x / 4", &parser.AST{Node: &parser.Node{Type: parser.NDec, Value: "0.25"}}),
+	)
+
+	It("works with optimized asts", func() {
+		ast, errs := parser.Parse("(1 + 2) * x")
+		Expect(errs).To(BeNil())
+		oast, err := optimizer.Optimize(&ast)
+		Expect(err).To(BeNil())
+
+		d, err := derivative.Derive(oast, "x")
+		Expect(err).To(BeNil())
+		Expect(d).To(Equal(&parser.AST{Node: &parser.Node{Type: parser.NInt, Value: "3"}}))
+	})
+
+	It("doesn't modify the given ast", func() {
+		ast, errs := parser.Parse("x * sin(x)")
+		Expect(errs).To(BeNil())
+		expected, _ := parser.Parse("x * sin(x)")
+
+		_, err := derivative.Derive(&ast, "x")
+		Expect(err).To(BeNil())
+		Expect(ast).To(Equal(expected))
+	})
+
+	DescribeTable("errors",
+		func(ast *parser.AST, expErr error) {
+			d, err := derivative.Derive(ast, "x")
+			Expect(d).To(BeNil())
+			Expect(err).To(Equal(expErr))
+		},
+		Entry("not differentiable operator", &parser.AST{Node: &parser.Node{
+			Type:       parser.NMod,
+			LeftChild:  &parser.Node{Type: parser.NVar, Value: "x"},
+			RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+		}}, derivative.ErrorNotDifferentiable),
+		Entry("missing left child", &parser.AST{Node: &parser.Node{
+			Type:       parser.NAdd,
+			RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+		}}, derivative.ErrorMissingLeftChild),
+		Entry("missing right child", &parser.AST{Node: &parser.Node{
+			Type:      parser.NAdd,
+			LeftChild: &parser.Node{Type: parser.NInt, Value: "2"},
+		}}, derivative.ErrorMissingRightChild),
+		Entry("missing function argument", &parser.AST{Node: &parser.Node{
+			Type: parser.NFnSin,
+		}}, derivative.ErrorMissingFunctionArgument),
+		Entry("invalid node type", &parser.AST{Node: &parser.Node{
+			Type: 3000,
+		}}, derivative.ErrorInvalidNodeType),
+	)
+
+	It("returns an empty ast for empty input", func() {
+		d, errs := derivative.DeriveString("", "x")
+		Expect(errs).To(BeNil())
+		Expect(d).To(Equal(&parser.AST{}))
+
+		d, err := derivative.Derive(&parser.AST{}, "x")
+		Expect(err).To(BeNil())
+		Expect(d).To(Equal(&parser.AST{}))
+	})
+
+	It("returns parser errors", func() {
+		d, errs := derivative.DeriveString("1 + $", "x")
+		Expect(d).To(BeNil())
+		Expect(errs).To(Equal([]error{parser.ErrorExpectedNumberOrVariable}))
+	})
+
+	It("keeps failing constant calculations", func() {
+		d, errs := derivative.DeriveString("x / 0", "x")
+		Expect(errs).To(BeNil())
+		Expect(d.Node.Type).To(Equal(parser.NDiv))
+	})
+})
@@ -0,0 +1,81 @@
+package derivative
+
+import (
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/parser"
+)
+
+// simplify recursively simplifies a tree, that only consists of parser nodes.
+// Constant operations and functions get calculated and the neutral and
+// absorbing elements of addition, subtraction, multiplication and division get
+// removed. Calculations, that fail (e.g. a division by zero) are kept as is.
+// This keeps the trees of the differentiation rules small, before they get
+// handed to the simplifier.
+func simplify(n parser.INode) parser.INode {
+	if parser.IsOperator(n) {
+		return simplifyOperator(n.GetType(), simplify(n.Left()), simplify(n.Right()))
+	}
+
+	if parser.IsFunction(n) {
+		arg := simplify(n.Left())
+		if value, ok := numberValue(arg); ok {
+			result, err := calculator.CalculateFunction(value, n.GetType())
+			if err == nil {
+				return newNumber(result)
+			}
+		}
+
+		return function(n.GetType(), arg)
+	}
+
+	return n
+}
+
+// simplifyOperator simplifies an operator with already simplified children.
+func simplifyOperator(t parser.NodeType, l, r parser.INode) parser.INode {
+	lv, lok := numberValue(l)
+	rv, rok := numberValue(r)
+
+	if lok && rok {
+		result, err := calculator.CalculateOperator(lv, rv, t)
+		if err == nil {
+			return newNumber(result)
+		}
+	}
+
+	switch t {
+	case parser.NAdd:
+		if lok && lv == 0 {
+			return r
+		}
+		if rok && rv == 0 {
+			return l
+		}
+	case parser.NSub:
+		if rok && rv == 0 {
+			return l
+		}
+		if lok && lv == 0 {
+			return simplifyOperator(parser.NMult, newNumber(-1), r)
+		}
+	case parser.NMult:
+		if lok && lv == 0 || rok && rv == 0 {
+			return newNumber(0)
+		}
+		if lok && lv == 1 {
+			return r
+		}
+		if rok && rv == 1 {
+			return l
+		}
+	case parser.NDiv:
+		if rok && rv == 1 {
+			return l
+		}
+		if lok && lv == 0 && !(rok && rv == 0) {
+			return newNumber(0)
+		}
+	}
+
+	return operator(t, l, r)
+}
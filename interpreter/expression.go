@@ -0,0 +1,113 @@
+package interpreter
+
+import (
+	"context"
+
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/parser"
+)
+
+// Expression holds a parsed and optionally optimized ast.
+// An expression never gets modified after its creation. Therefore the same
+// expression can be evaluated concurrently with different environments.
+type Expression struct {
+	ast    parser.IAST
+	limits Limits
+}
+
+// NewExpression parses a string and returns a new expression.
+// Returns errors if lexing or parsing failed.
+func NewExpression(str string) (*Expression, []error) {
+	if len(str) == 0 {
+		return &Expression{}, nil
+	}
+
+	ast, errors := parser.Parse(str)
+	if errors != nil {
+		return nil, errors
+	}
+
+	return &Expression{ast: &ast}, nil
+}
+
+// NewExpressionWithOptions behaves like NewExpression, but parses the string
+// with the given options (see parser.Options). The percent mode of the options
+// gets applied before the expression gets optimized.
+func NewExpressionWithOptions(str string, options parser.Options) (*Expression, []error) {
+	if len(str) == 0 {
+		return &Expression{}, nil
+	}
+
+	ast, errors := parser.ParseWithOptions(str, options)
+	if errors != nil {
+		return nil, errors
+	}
+
+	return &Expression{ast: &ast}, nil
+}
+
+// NewOptimizedExpression parses a string and returns a new optimized
+// expression.
+// Returns errors if lexing, parsing or optimizing failed.
+func NewOptimizedExpression(str string) (*Expression, []error) {
+	e, errors := NewExpression(str)
+	if errors != nil {
+		return nil, errors
+	}
+
+	oe, err := e.Optimize()
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return oe, nil
+}
+
+// NewExpressionFromAST returns a new expression from an ast.
+// The ast must not be modified afterwards.
+func NewExpressionFromAST(ast parser.IAST) *Expression {
+	return &Expression{ast: ast}
+}
+
+// AST returns the ast of the expression.
+func (e *Expression) AST() parser.IAST {
+	return e.ast
+}
+
+// Optimize returns a new optimized expression. The expression itself stays
+// untouched.
+func (e *Expression) Optimize() (*Expression, error) {
+	if parser.IsEmpty(e.ast) || e.ast.Optimized() {
+		return e, nil
+	}
+
+	oast, err := optimizer.Optimize(e.ast)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Expression{ast: oast, limits: e.limits}, nil
+}
+
+// WithLimits returns a new expression, that enforces the given limits during
+// evaluation.
+func (e *Expression) WithLimits(limits Limits) *Expression {
+	return &Expression{ast: e.ast, limits: limits}
+}
+
+// Eval evaluates the expression with the variables of the given environment.
+// It is safe to call Eval from multiple go routines.
+func (e *Expression) Eval(env Env) (float64, error) {
+	return e.EvalContext(context.Background(), env)
+}
+
+// EvalContext behaves like Eval, but stops the evaluation, when the context
+// gets canceled or its deadline is exceeded. In that case the error of the
+// context gets returned.
+func (e *Expression) EvalContext(ctx context.Context, env Env) (float64, error) {
+	if parser.IsEmpty(e.ast) {
+		return 0, nil
+	}
+
+	return evaluate(ctx, e.ast, env, e.limits, nil)
+}
@@ -0,0 +1,111 @@
+package interpreter_test
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/parser"
+)
+
+var _ = Describe("Expression", func() {
+	DescribeTable("Eval()",
+		func(in string, env interpreter.Env, out float64, expErr error) {
+			for _, optimized := range []bool{false, true} {
+				var e *interpreter.Expression
+				var errs []error
+				if optimized {
+					e, errs = interpreter.NewOptimizedExpression(in)
+				} else {
+					e, errs = interpreter.NewExpression(in)
+				}
+				Expect(errs).To(BeNil())
+
+				result, err := e.Eval(env)
+				Ω(result).Should(BeNumerically("==", out))
+				if expErr != nil {
+					Expect(err).To(Equal(expErr))
+				} else {
+					Expect(err).To(BeNil())
+				}
+			}
+		},
+		Entry("empty", "", nil, 0.0, nil),
+		Entry("without vars", "(1 + 2) * 3", nil, 9.0, nil),
+		Entry("with vars", "(a + 2) * b", interpreter.Env{"a": 1, "b": 3}, 9.0, nil),
+		Entry("with nil env", "a", nil, 0.0, interpreter.ErrorVariableNotDefined),
+		Entry("with missing var", "a + b", interpreter.Env{"a": 1}, 0.0, interpreter.ErrorVariableNotDefined),
+	)
+
+	DescribeTable("Eval() with the percent of value mode",
+		func(in string, env interpreter.Env, out float64) {
+			e, errs := interpreter.NewExpressionWithOptions(in, parser.Options{Percent: parser.PercentOfValue})
+			Expect(errs).To(BeNil())
+
+			oe, err := e.Optimize()
+			Expect(err).To(BeNil())
+
+			for _, e := range []*interpreter.Expression{e, oe} {
+				result, err := e.Eval(env)
+				Expect(err).To(BeNil())
+				Ω(result).Should(BeNumerically("==", out))
+			}
+		},
+		Entry("empty", "", nil, 0.0),
+		Entry("addition", "200 + 15%", nil, 230.0),
+		Entry("subtraction", "200 - 15%", nil, 170.0),
+		Entry("multiplication", "200 * 15%", nil, 30.0),
+		Entry("with vars", "a + b%", interpreter.Env{"a": 200, "b": 15}, 230.0),
+	)
+
+	It("returns parser errors", func() {
+		e, errs := interpreter.NewExpression("1 + $")
+		Expect(e).To(BeNil())
+		Expect(errs).To(Equal([]error{parser.ErrorExpectedNumberOrVariable}))
+	})
+
+	It("evaluates empty asts to 0", func() {
+		e := interpreter.NewExpressionFromAST(&parser.AST{})
+		Expect(e.Eval(nil)).To(Equal(0.0))
+
+		oe, err := e.Optimize()
+		Expect(err).To(BeNil())
+		Expect(oe.Eval(nil)).To(Equal(0.0))
+	})
+
+	It("doesn't modify the expression, when optimizing", func() {
+		e, errs := interpreter.NewExpression("(1 + 2) * a")
+		Expect(errs).To(BeNil())
+
+		oe, err := e.Optimize()
+		Expect(err).To(BeNil())
+		Expect(oe.AST().Optimized()).To(BeTrue())
+		Expect(e.AST().Optimized()).To(BeFalse())
+		Expect(e.AST().Root().Left().GetType()).To(Equal(parser.NAdd))
+	})
+
+	It("can be evaluated concurrently with different environments", func() {
+		e, errs := interpreter.NewOptimizedExpression("(a + 2) * 4 - (4 / 2) * b")
+		Expect(errs).To(BeNil())
+
+		var wg sync.WaitGroup
+		results := make([]float64, 50)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				x := float64(i)
+				results[i], _ = e.Eval(interpreter.Env{"a": x, "b": x * 2})
+			}(i)
+		}
+		wg.Wait()
+
+		for i, result := range results {
+			x := float64(i)
+			Ω(result).Should(BeNumerically("==", (x+2)*4-2*x*2))
+		}
+	})
+})
@@ -1,7 +1,9 @@
 package interpreter
 
 import (
+	"context"
 	"errors"
+	"math"
 
 	"github.com/relnod/calcgo/interpreter/calculator"
 	"github.com/relnod/calcgo/interpreter/optimizer"
@@ -19,12 +21,21 @@ var (
 	ErrorVariableNotDefined     = errors.New("Error: A variable was not defined")
 )
 
-// Interpreter holds state of interpreter
+// Env holds the values of variables used during an evaluation.
+type Env map[string]float64
+
+// Interpreter holds state of interpreter.
+// An interpreter is not safe for concurrent use. Use an Expression to evaluate
+// the same ast from multiple go routines.
 type Interpreter struct {
 	str              string
 	ast              parser.IAST
-	vars             map[string]float64
+	vars             Env
+	limits           Limits
+	options          parser.Options
 	optimizerEnabled bool
+	memoEnabled      bool
+	memo             *memo
 }
 
 // NewInterpreter returns a new interpreter from a string
@@ -32,7 +43,7 @@ func NewInterpreter(str string) *Interpreter {
 	return &Interpreter{
 		str:              str,
 		ast:              nil,
-		vars:             make(map[string]float64),
+		vars:             make(Env),
 		optimizerEnabled: false,
 	}
 }
@@ -42,14 +53,22 @@ func NewInterpreterFromAST(ast parser.IAST) *Interpreter {
 	return &Interpreter{
 		str:              "",
 		ast:              ast,
-		vars:             make(map[string]float64),
+		vars:             make(Env),
 		optimizerEnabled: false,
 	}
 }
 
 // SetVar sets the value of a variable
 func (i *Interpreter) SetVar(name string, value float64) {
+	// Compare the bits, so changing 0 to -0 isn't ignored.
+	if old, ok := i.vars[name]; ok && math.Float64bits(old) == math.Float64bits(value) {
+		return
+	}
+
 	i.vars[name] = value
+	if i.memo != nil {
+		i.memo.invalidate(name)
+	}
 }
 
 // EnableOptimizer enables optimization of the ast.
@@ -58,23 +77,50 @@ func (i *Interpreter) EnableOptimizer() {
 	i.optimizerEnabled = true
 }
 
+// EnableMemoization enables caching of the results of all subtrees between
+// calls of GetResult(). Setting a variable only invalidates the subtrees, that
+// depend on the variable. This speeds up the repeated evaluation of large
+// expressions, where only a few variables change.
+func (i *Interpreter) EnableMemoization() {
+	i.memoEnabled = true
+}
+
+// SetLimits sets the limits, that get enforced during GetResult() and
+// GetResultContext().
+func (i *Interpreter) SetLimits(limits Limits) {
+	i.limits = limits
+}
+
+// SetParseOptions sets the options, that get used to parse the string of the
+// interpreter, like the percent mode (see parser.Options). Optimization
+// happens after parsing, so it respects the percent mode. The options have no
+// effect on interpreters created from an ast.
+func (i *Interpreter) SetParseOptions(options parser.Options) {
+	i.options = options
+}
+
 // GetResult interprets the ast.
 // All variables have to be set up to this point
 //
 // If the interpreter was initialized with a string,
 // the ast gets generated first
 func (i *Interpreter) GetResult() (float64, []error) {
+	return i.GetResultContext(context.Background())
+}
+
+// GetResultContext behaves like GetResult, but stops the interpretation, when
+// the context gets canceled or its deadline is exceeded. In that case the
+// error of the context gets returned.
+func (i *Interpreter) GetResultContext(ctx context.Context) (float64, []error) {
 	if i.str == "" && i.ast == nil {
 		return 0, nil
 	}
 
-	if i.ast == nil {
-		ast, errors := parser.Parse(i.str)
-		if errors != nil {
-			return 0, errors
-		}
-
-		i.ast = &ast
+	if errors := i.parse(); errors != nil {
+		return 0, errors
+	}
+	if parser.IsEmpty(i.ast) {
+		return 0, nil
 	}
 
 	var result float64
@@ -88,8 +134,11 @@ func (i *Interpreter) GetResult() (float64, []error) {
 		i.ast = oast
 	}
 
-	result, err = i.ast.Root().Calculate(i.calcVisitor)
+	if i.memoEnabled && (i.memo == nil || i.memo.root != i.ast.Root()) {
+		i.memo = newMemo(i.ast.Root())
+	}
 
+	result, err = evaluate(ctx, i.ast, i.vars, i.limits, i.memo)
 	if err != nil {
 		return 0, []error{err}
 	}
@@ -97,6 +146,22 @@ func (i *Interpreter) GetResult() (float64, []error) {
 	return result, nil
 }
 
+// parse generates the ast, if the interpreter was initialized with a string.
+func (i *Interpreter) parse() []error {
+	if i.ast != nil {
+		return nil
+	}
+
+	ast, errors := parser.ParseWithOptions(i.str, i.options)
+	if errors != nil {
+		return errors
+	}
+
+	i.ast = &ast
+
+	return nil
+}
+
 // Interpret interprets a given string.
 // Returns errors if lexing, parsing or interpreting failed
 //
@@ -148,10 +213,18 @@ func InterpretAST(ast parser.IAST) (float64, error) {
 	return result, nil
 }
 
-func (i *Interpreter) calcVisitor(n parser.INode) (float64, error) {
+// calcVisitor is the calculation visitor, that interprets a node using the
+// variables of the environment.
+func (e Env) calcVisitor(n parser.INode) (float64, error) {
+	return e.interpretNode(n, e.calcVisitor)
+}
+
+// interpretNode interprets a single node. Child nodes get calculated with the
+// given visitor.
+func (e Env) interpretNode(n parser.INode, visitor parser.CalcVisitor) (float64, error) {
 	switch n.GetType() {
 	case parser.NVar:
-		return i.interpretVariable(n)
+		return e.interpretVariable(n)
 	case parser.NInt:
 		return calculator.ConvertInteger(n.GetValue())
 	case parser.NDec:
@@ -162,14 +235,20 @@ func (i *Interpreter) calcVisitor(n parser.INode) (float64, error) {
 		return calculator.ConvertHex(n.GetValue())
 	case parser.NExp:
 		return calculator.ConvertExponential(n.GetValue())
+	case parser.NOct:
+		return calculator.ConvertOct(n.GetValue())
+	case parser.NSci:
+		return calculator.ConvertScientific(n.GetValue())
+	case parser.NHexFloat:
+		return calculator.ConvertHexFloat(n.GetValue())
 	}
 
 	if parser.IsOperator(n) {
-		return i.interpretOperator(n)
+		return e.interpretOperator(n, visitor)
 	}
 
 	if parser.IsFunction(n) {
-		return i.interpretFunction(n)
+		return e.interpretFunction(n, visitor)
 	}
 
 	return 0, ErrorInvalidNodeType
@@ -177,8 +256,8 @@ func (i *Interpreter) calcVisitor(n parser.INode) (float64, error) {
 
 // interpretVariable interprets a variable node.
 // Returns an error if the variable is not defined.
-func (i *Interpreter) interpretVariable(n parser.INode) (float64, error) {
-	number, ok := i.vars[n.GetValue()]
+func (e Env) interpretVariable(n parser.INode) (float64, error) {
+	number, ok := e[n.GetValue()]
 	if ok {
 		return number, nil
 	}
@@ -187,8 +266,8 @@ func (i *Interpreter) interpretVariable(n parser.INode) (float64, error) {
 }
 
 // interpretOperator recursively interprets an operator node.
-func (i *Interpreter) interpretOperator(n parser.INode) (float64, error) {
-	left, right, err := i.getInterpretedNodeChilds(n)
+func (e Env) interpretOperator(n parser.INode, visitor parser.CalcVisitor) (float64, error) {
+	left, right, err := e.getInterpretedNodeChilds(n, visitor)
 	if err != nil {
 		return 0, err
 	}
@@ -197,8 +276,17 @@ func (i *Interpreter) interpretOperator(n parser.INode) (float64, error) {
 }
 
 // interpretFunction interprets a function node
-func (i *Interpreter) interpretFunction(n parser.INode) (float64, error) {
-	left, err := n.Left().Calculate(i.calcVisitor)
+func (e Env) interpretFunction(n parser.INode, visitor parser.CalcVisitor) (float64, error) {
+	if parser.IsBinaryFunction(n) {
+		left, right, err := e.getInterpretedNodeChilds(n, visitor)
+		if err != nil {
+			return 0, err
+		}
+
+		return calculator.CalculateBinaryFunction(left, right, n.GetType())
+	}
+
+	left, err := n.Left().Calculate(visitor)
 	if err != nil {
 		return 0, err
 	}
@@ -208,7 +296,7 @@ func (i *Interpreter) interpretFunction(n parser.INode) (float64, error) {
 
 // getInterpretedNodeChilds returns the interpreted child nodes of a given node.
 // Both child nodes have to be defined. Retruns an error otherwise.
-func (i *Interpreter) getInterpretedNodeChilds(n parser.INode) (float64, float64, error) {
+func (e Env) getInterpretedNodeChilds(n parser.INode, visitor parser.CalcVisitor) (float64, float64, error) {
 	if n.Left() == nil {
 		return 0, 0, ErrorMissingLeftChild
 	}
@@ -216,11 +304,11 @@ func (i *Interpreter) getInterpretedNodeChilds(n parser.INode) (float64, float64
 		return 0, 0, ErrorMissingRightChild
 	}
 
-	left, err := n.Left().Calculate(i.calcVisitor)
+	left, err := n.Left().Calculate(visitor)
 	if err != nil {
 		return 0, 0, err
 	}
-	right, err := n.Right().Calculate(i.calcVisitor)
+	right, err := n.Right().Calculate(visitor)
 	if err != nil {
 		return 0, 0, err
 	}
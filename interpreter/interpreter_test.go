@@ -83,6 +83,36 @@ func DescribeInterpreter(newInterpreter newInterpreterFnc) {
 			Entry("negative 3", "-2^3", -8.0, nil),
 		)
 
+		DescribeTable("octal", test,
+			Entry("positive", "0o17", 15.0, nil),
+			Entry("negative", "-0o17", -15.0, nil),
+		)
+
+		DescribeTable("scientific", test,
+			Entry("negative exponent", "1.5e-3", 0.0015, nil),
+			Entry("uppercase", "6.02E23", 6.02e23, nil),
+			Entry("without point", "1e3", 1000.0, nil),
+			Entry("negative", "-2e2", -200.0, nil),
+		)
+
+		DescribeTable("hex float", test,
+			Entry("positive", "0x1.8p1", 3.0, nil),
+			Entry("negative exponent", "0x1p-2", 0.25, nil),
+		)
+
+		DescribeTable("other notations", test,
+			Entry("lowercase hex", "0xff", 255.0, nil),
+			Entry("leading dot", ".5", 0.5, nil),
+			Entry("negative leading dot", "-.5", -0.5, nil),
+			Entry("digit separators", "1_000_000", 1000000.0, nil),
+			Entry("digit separators in hex", "0xFF_FF", 65535.0, nil),
+			Entry("digit separators in decimal", "1_000.000_1", 1000.0001, nil),
+			Entry("digit separator after hex prefix", "0x_ff", 255.0, nil),
+			Entry("digit separator after octal prefix", "0o_17", 15.0, nil),
+			Entry("digit separator after binary prefix", "0b_1", 1.0, nil),
+			Entry("digit separator after hex float prefix", "0x_1.8p1", 3.0, nil),
+		)
+
 		DescribeTable("errors", test,
 			Entry("not a number", "$", 0.0, []error{parser.ErrorExpectedNumberOrVariable}),
 		)
@@ -119,6 +149,12 @@ func DescribeInterpreter(newInterpreter newInterpreterFnc) {
 			Entry("2", "5 % 6", 5.0, nil),
 			Entry("3", "12 % 6", 0.0, nil),
 			Entry("4", "13 % 6", 1.0, nil),
+			Entry("negative dividend", "-7 % 3", -1.0, nil),
+			Entry("negative divisor", "7 % -3", 1.0, nil),
+			Entry("decimals", "5.5 % 2", 1.5, nil),
+			Entry("large dividend", "1e300 % 7", math.Mod(1e300, 7), nil),
+			Entry("by zero", "7 % 0", 0.0, []error{calculator.ErrorDivisionByZero}),
+			Entry("negative by zero", "-7 % (1 - 1)", 0.0, []error{calculator.ErrorDivisionByZero}),
 		)
 
 		DescribeTable("binary or", test,
@@ -136,6 +172,78 @@ func DescribeInterpreter(newInterpreter newInterpreterFnc) {
 			Entry("2", "5 & 0", 0.0, nil),
 		)
 
+		DescribeTable("shifts and bitwise not", test,
+			Entry("shift left", "1 << 4", 16.0, nil),
+			Entry("shift right", "0xf0 >> 4", 15.0, nil),
+			Entry("unsigned shift right", "-1 >>> 60", 15.0, nil),
+			Entry("precedence", "1 + 1 << 3", 9.0, nil),
+			Entry("register mask", "(0xff << 8 | 0x0f) & ~0xf00", 61455.0, nil),
+			Entry("bitwise not", "~5", -6.0, nil),
+			Entry("bitwise not before postfix operator", "~3!", -7.0, nil),
+			Entry("decimal operand", "1.5 & 1", 0.0, []error{calculator.ErrorNotAnInteger}),
+			Entry("bitwise not of decimal", "~(1 / 2)", 0.0, []error{calculator.ErrorNotAnInteger}),
+			Entry("negative shift count", "1 << -1", 0.0, []error{calculator.ErrorOutOfDomain}),
+			Entry("shift overflow", "1 << 64", 0.0, []error{calculator.ErrorOverflow}),
+		)
+
+		DescribeTable("without whitespace", test,
+			Entry("addition", "1+2", 3.0, nil),
+			Entry("subtraction", "10-2-3", 5.0, nil),
+			Entry("negative numbers", "-1--2", 1.0, nil),
+			Entry("brackets", "(3*4)", 12.0, nil),
+			Entry("precedence", "1+2*3", 7.0, nil),
+			Entry("bitwise operators", "0b101&0x3|8", 9.0, nil),
+			Entry("functions", "sqrt(4)-1", 1.0, nil),
+			Entry("subtraction after brackets", "(1+2)-1", 2.0, nil),
+		)
+
+		DescribeTable("integer functions", test,
+			Entry("factorial", "5! / 2", 60.0, nil),
+			Entry("factorial of brackets", "(1 + 2)!", 6.0, nil),
+			Entry("gamma", "gamma(5)", 24.0, nil),
+			Entry("binom", "binom(5, 2)", 10.0, nil),
+			Entry("perm", "perm(5, 2)", 20.0, nil),
+			Entry("gcd", "gcd(12, -18)", 6.0, nil),
+			Entry("lcm", "lcm(4, 6)", 12.0, nil),
+			Entry("isprime", "isprime(97) + isprime(91)", 1.0, nil),
+			Entry("mod", "mod(-7, 3)", 2.0, nil),
+			Entry("overflow", "171!", 0.0, []error{calculator.ErrorOverflow}),
+			Entry("out of domain", "(0 - 1)!", 0.0, []error{calculator.ErrorOutOfDomain}),
+			Entry("not an integer", "binom(2.5, 1)", 0.0, []error{calculator.ErrorNotAnInteger}),
+			Entry("division by zero", "mod(1, 0)", 0.0, []error{calculator.ErrorDivisionByZero}),
+			Entry("missing argument", "gcd(1)", 0.0, []error{parser.ErrorWrongNumberOfArguments}),
+		)
+
+		DescribeTable("percent", test,
+			Entry("number", "15%", 0.15, nil),
+			Entry("addition", "200 + 15%", 200.15, nil),
+			Entry("multiplication", "200 * 15%", 30.0, nil),
+			Entry("brackets", "(10 + 40)% * 4", 2.0, nil),
+			Entry("modulo", "15%4", 3.0, nil),
+			Entry("modulo with whitespace", "15 % 4", 3.0, nil),
+			Entry("modulo with whitespace after the operator", "15% 4", 3.0, nil),
+			Entry("percent before an operator", "15%  + 1", 1.15, nil),
+			Entry("percent before a minus", "7% - 3", -2.93, nil),
+			Entry("modulo of a negative number", "7%-3", 1.0, nil),
+			Entry("modulo of a negative number with whitespace", "7% -3", 1.0, nil),
+			Entry("modulo of a negative number before the operator", "7 %-3", 1.0, nil),
+			Entry("modulo of bitwise not", "7%~1", 1.0, nil),
+		)
+
+		DescribeTable("percent of value",
+			func(in string, out float64) {
+				i := newInterpreter(in)
+				i.SetParseOptions(parser.Options{Percent: parser.PercentOfValue})
+				result, errs := i.GetResult()
+				Expect(errs).To(BeNil())
+				Ω(result).Should(BeNumerically("==", out))
+			},
+			Entry("addition", "200 + 15%", 230.0),
+			Entry("subtraction", "200 - 15%", 170.0),
+			Entry("multiplication", "200 * 15%", 30.0),
+			Entry("addition in brackets", "(50 + 10%) * 2", 110.0),
+		)
+
 		DescribeTable("'multiplication and division before addition and subtraction' rule", test,
 			Entry("addition, then multiplication", "1 + 2 * 3", 7.0, nil),
 			Entry("addition, then division", "1 + 4 / 2", 3.0, nil),
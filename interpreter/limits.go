@@ -0,0 +1,139 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"unsafe"
+
+	"github.com/relnod/calcgo/parser"
+)
+
+// Errors, that occur when a limit is exceeded
+var (
+	ErrorMaxDepthExceeded  = errors.New("Error: Maximum ast depth exceeded")
+	ErrorMaxNodesExceeded  = errors.New("Error: Maximum number of nodes exceeded")
+	ErrorMaxStepsExceeded  = errors.New("Error: Maximum number of evaluation steps exceeded")
+	ErrorMaxMemoryExceeded = errors.New("Error: Maximum memory exceeded")
+)
+
+// nodeSize is the estimated size of a single node in bytes.
+const nodeSize = int(unsafe.Sizeof(parser.Node{}))
+
+// contextCheckInterval defines after how many evaluation steps the context
+// gets checked.
+const contextCheckInterval = 64
+
+// Limits defines the limits of an evaluation. A limit with the value 0 is
+// disabled.
+type Limits struct {
+	// MaxDepth limits the depth of the ast.
+	MaxDepth int
+
+	// MaxNodes limits the number of nodes of the ast.
+	MaxNodes int
+
+	// MaxSteps limits the number of evaluated nodes.
+	MaxSteps int
+
+	// MaxMemory limits the estimated memory in bytes, that is needed to hold
+	// the ast.
+	MaxMemory int
+}
+
+// enabled returns true, if at least one limit is set.
+func (l Limits) enabled() bool {
+	return l != Limits{}
+}
+
+// check walks the ast and returns an error if a limit for the depth, the
+// number of nodes or the memory is exceeded. The walk stops as soon as a limit
+// is exceeded.
+func (l Limits) check(n parser.INode) error {
+	var nodes, memory int
+
+	var walk func(n parser.INode, depth int) error
+	walk = func(n parser.INode, depth int) error {
+		if n == nil {
+			return nil
+		}
+
+		nodes++
+		memory += nodeSize + len(n.GetValue())
+
+		if l.MaxDepth > 0 && depth > l.MaxDepth {
+			return ErrorMaxDepthExceeded
+		}
+		if l.MaxNodes > 0 && nodes > l.MaxNodes {
+			return ErrorMaxNodesExceeded
+		}
+		if l.MaxMemory > 0 && memory > l.MaxMemory {
+			return ErrorMaxMemoryExceeded
+		}
+
+		if err := walk(n.Left(), depth+1); err != nil {
+			return err
+		}
+		return walk(n.Right(), depth+1)
+	}
+
+	return walk(n, 1)
+}
+
+// limitedEvaluator evaluates an ast, while enforcing the step limit and
+// checking the context.
+type limitedEvaluator struct {
+	ctx     context.Context
+	env     Env
+	limits  Limits
+	steps   int
+	visitor parser.CalcVisitor
+}
+
+// calcVisitor is the calculation visitor of the limited evaluator.
+func (l *limitedEvaluator) calcVisitor(n parser.INode) (float64, error) {
+	l.steps++
+	if l.limits.MaxSteps > 0 && l.steps > l.limits.MaxSteps {
+		return 0, ErrorMaxStepsExceeded
+	}
+	if l.steps%contextCheckInterval == 0 {
+		if err := l.ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	return l.env.interpretNode(n, l.visitor)
+}
+
+// evaluate evaluates an ast with the variables of env. The context and limits
+// only get checked, if they can cancel the evaluation. If m is not nil, cached
+// results of subtrees get used and don't count as evaluation steps.
+func evaluate(ctx context.Context, ast parser.IAST, env Env, limits Limits, m *memo) (float64, error) {
+	if ctx.Done() == nil && !limits.enabled() {
+		if m == nil {
+			return ast.Root().Calculate(env.calcVisitor)
+		}
+
+		var visitor parser.CalcVisitor
+		visitor = m.wrap(func(n parser.INode) (float64, error) {
+			return env.interpretNode(n, visitor)
+		})
+
+		return ast.Root().Calculate(visitor)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := limits.check(ast.Root()); err != nil {
+		return 0, err
+	}
+
+	l := &limitedEvaluator{ctx: ctx, env: env, limits: limits}
+	l.visitor = l.calcVisitor
+	if m != nil {
+		l.visitor = m.wrap(l.calcVisitor)
+	}
+
+	return ast.Root().Calculate(l.visitor)
+}
@@ -0,0 +1,117 @@
+package interpreter_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+)
+
+// cancelAfterContext is a context, that gets canceled after Err() was called n
+// times.
+type cancelAfterContext struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfterContext) Done() <-chan struct{} {
+	return make(chan struct{})
+}
+
+func (c *cancelAfterContext) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+// longExpression returns an expression with n additions.
+func longExpression(n int) string {
+	return "1" + strings.Repeat(" + 1", n)
+}
+
+var _ = Describe("Limits", func() {
+	DescribeTable("GetResult() with limits",
+		func(in string, limits interpreter.Limits, out float64, expErr error) {
+			i := interpreter.NewInterpreter(in)
+			i.SetLimits(limits)
+			result, errs := i.GetResult()
+			Ω(result).Should(BeNumerically("==", out))
+			if expErr != nil {
+				Expect(errs).To(Equal([]error{expErr}))
+			} else {
+				Expect(errs).To(BeNil())
+			}
+
+			e, errs := interpreter.NewExpression(in)
+			Expect(errs).To(BeNil())
+			result, err := e.WithLimits(limits).Eval(nil)
+			Ω(result).Should(BeNumerically("==", out))
+			if expErr != nil {
+				Expect(err).To(Equal(expErr))
+			} else {
+				Expect(err).To(BeNil())
+			}
+		},
+		Entry("no limits", "1 + 2 * 3", interpreter.Limits{}, 7.0, nil),
+		Entry("within all limits", "1 + 2 * 3", interpreter.Limits{
+			MaxDepth: 3, MaxNodes: 5, MaxSteps: 5, MaxMemory: 1 << 20,
+		}, 7.0, nil),
+		Entry("depth exceeded", "1 + 2 * 3", interpreter.Limits{MaxDepth: 2}, 0.0,
+			interpreter.ErrorMaxDepthExceeded),
+		Entry("nodes exceeded", "1 + 2 * 3", interpreter.Limits{MaxNodes: 4}, 0.0,
+			interpreter.ErrorMaxNodesExceeded),
+		Entry("steps exceeded", "1 + 2 * 3", interpreter.Limits{MaxSteps: 4}, 0.0,
+			interpreter.ErrorMaxStepsExceeded),
+		Entry("memory exceeded", longExpression(100), interpreter.Limits{MaxMemory: 1024}, 0.0,
+			interpreter.ErrorMaxMemoryExceeded),
+	)
+
+	Describe("context", func() {
+		It("returns the error of a canceled context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			i := interpreter.NewInterpreter("1 + 1")
+			result, errs := i.GetResultContext(ctx)
+			Ω(result).Should(BeNumerically("==", 0))
+			Expect(errs).To(Equal([]error{context.Canceled}))
+		})
+
+		It("returns the error of an exceeded deadline", func() {
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			defer cancel()
+
+			e, errs := interpreter.NewExpression("1 + 1")
+			Expect(errs).To(BeNil())
+			_, err := e.EvalContext(ctx, nil)
+			Expect(err).To(Equal(context.DeadlineExceeded))
+		})
+
+		It("stops during the evaluation", func() {
+			ctx := &cancelAfterContext{Context: context.Background(), n: 1}
+
+			e, errs := interpreter.NewExpression(longExpression(1000))
+			Expect(errs).To(BeNil())
+			_, err := e.EvalContext(ctx, nil)
+			Expect(err).To(Equal(context.Canceled))
+		})
+
+		It("evaluates normally with an active context", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			e, errs := interpreter.NewExpression(longExpression(1000))
+			Expect(errs).To(BeNil())
+			result, err := e.EvalContext(ctx, nil)
+			Expect(err).To(BeNil())
+			Ω(result).Should(BeNumerically("==", 1001))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package interpreter
+
+import "github.com/relnod/calcgo/parser"
+
+// memoResult holds the cached result of a subtree.
+type memoResult struct {
+	value float64
+	valid bool
+}
+
+// memo caches the results of all subtrees of an ast. A cached result stays
+// valid until one of the variables, the subtree depends on, changes.
+type memo struct {
+	root    parser.INode
+	results map[parser.INode]*memoResult
+
+	// vars maps a variable to the results of all subtrees, that contain the
+	// variable.
+	vars map[string][]*memoResult
+}
+
+// newMemo returns a new memo for the tree with the given root node.
+func newMemo(root parser.INode) *memo {
+	m := &memo{
+		root:    root,
+		results: make(map[parser.INode]*memoResult),
+		vars:    make(map[string][]*memoResult),
+	}
+	m.index(root, nil)
+
+	return m
+}
+
+// index recursively adds a result for n and all of its child nodes. For each
+// variable, the results of all its ancestors get registered.
+func (m *memo) index(n parser.INode, ancestors []*memoResult) {
+	if parser.IsNil(n) {
+		return
+	}
+
+	r := &memoResult{}
+	m.results[n] = r
+	ancestors = append(ancestors, r)
+
+	if n.GetType() == parser.NVar {
+		name := n.GetValue()
+		m.vars[name] = append(m.vars[name], ancestors...)
+		return
+	}
+
+	m.index(n.Left(), ancestors[:len(ancestors):len(ancestors)])
+	m.index(n.Right(), ancestors[:len(ancestors):len(ancestors)])
+}
+
+// invalidate invalidates the results of all subtrees, that depend on the
+// given variable.
+func (m *memo) invalidate(name string) {
+	for _, r := range m.vars[name] {
+		r.valid = false
+	}
+}
+
+// wrap returns a calculation visitor, that returns cached results. Otherwise
+// the given visitor gets called and its result gets cached.
+func (m *memo) wrap(visitor parser.CalcVisitor) parser.CalcVisitor {
+	return func(n parser.INode) (float64, error) {
+		r, ok := m.results[n]
+		if ok && r.valid {
+			return r.value, nil
+		}
+
+		value, err := visitor(n)
+		if err != nil {
+			return 0, err
+		}
+
+		if ok {
+			r.value = value
+			r.valid = true
+		}
+
+		return value, nil
+	}
+}
@@ -0,0 +1,134 @@
+package interpreter_test
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/parser"
+)
+
+var _ = Describe("Memoization", func() {
+	type step struct {
+		name  string
+		value float64
+	}
+
+	DescribeTable("GetResult() with memoization returns the same results",
+		func(str string, steps []step) {
+			memo := interpreter.NewInterpreter(str)
+			memo.EnableMemoization()
+			plain := interpreter.NewInterpreter(str)
+
+			for _, s := range steps {
+				memo.SetVar(s.name, s.value)
+				plain.SetVar(s.name, s.value)
+
+				expected, expErrs := plain.GetResult()
+				result, errs := memo.GetResult()
+				Expect(result).To(Equal(expected))
+				Expect(errs).To(Equal(expErrs))
+			}
+		},
+		Entry("single variable", "(a + 2) * 4 - (4 / 6)", []step{
+			{"a", 1}, {"a", 2}, {"a", 2}, {"a", -3},
+		}),
+		Entry("multiple variables", "a * (b + 1) - sqrt(c) / (a - b)", []step{
+			{"a", 1}, {"b", 3}, {"c", 4}, {"b", 5}, {"a", 2}, {"c", 9},
+		}),
+		Entry("constant subtrees", "(1 + 2) * (3 + 4) + a * (5 - 6)", []step{
+			{"a", 1}, {"a", 2}, {"a", 3},
+		}),
+		Entry("undefined variables", "a + b", []step{
+			{"a", 1}, {"b", 2}, {"a", 3},
+		}),
+		Entry("function arguments", "sin(a) + cos(a * b)", []step{
+			{"a", 1}, {"b", 2}, {"a", 0},
+		}),
+	)
+
+	It("works together with the optimizer", func() {
+		i := interpreter.NewInterpreter("(1 + 2) * a + 4 / 2")
+		i.EnableMemoization()
+		i.SetVar("a", 1)
+		Expect(i.GetResult()).To(Equal(5.0))
+
+		i.EnableOptimizer()
+		i.SetVar("a", 2)
+		Expect(i.GetResult()).To(Equal(8.0))
+		i.SetVar("a", 3)
+		Expect(i.GetResult()).To(Equal(11.0))
+	})
+
+	It("works together with limits", func() {
+		i := interpreter.NewInterpreter("(1 + 2) * a + 4 / 2")
+		i.EnableMemoization()
+		i.SetLimits(interpreter.Limits{MaxSteps: 100})
+		i.SetVar("a", 1)
+		Expect(i.GetResult()).To(Equal(5.0))
+
+		i.SetVar("a", 2)
+		Expect(i.GetResult()).To(Equal(8.0))
+	})
+
+	It("only evaluates changed subtrees", func() {
+		i := interpreter.NewInterpreter("(1 + 2) * (3 + 4) + a")
+		i.EnableMemoization()
+		i.SetVar("a", 1)
+		Expect(i.GetResult()).To(Equal(22.0))
+
+		// Only the root and the variable need to be evaluated.
+		i.SetLimits(interpreter.Limits{MaxSteps: 1})
+		i.SetVar("a", 2)
+		_, errs := i.GetResult()
+		Expect(errs).To(Equal([]error{interpreter.ErrorMaxStepsExceeded}))
+
+		i.SetLimits(interpreter.Limits{MaxSteps: 2})
+		Expect(i.GetResult()).To(Equal(23.0))
+	})
+
+	It("works with empty asts", func() {
+		for _, i := range []*interpreter.Interpreter{
+			interpreter.NewInterpreterFromAST(&parser.AST{}),
+			interpreter.NewInterpreter("  "),
+		} {
+			i.EnableMemoization()
+			i.EnableOptimizer()
+			Expect(i.GetResult()).To(Equal(0.0))
+		}
+	})
+
+	It("recalculates, when a variable changes its sign from 0 to -0", func() {
+		for _, memoization := range []bool{false, true} {
+			i := interpreter.NewInterpreter("a * 2")
+			if memoization {
+				i.EnableMemoization()
+			}
+			i.SetVar("a", 0)
+			result, errs := i.GetResult()
+			Expect(errs).To(BeNil())
+			Expect(math.Signbit(result)).To(BeFalse())
+
+			i.SetVar("a", math.Copysign(0, -1))
+			result, errs = i.GetResult()
+			Expect(errs).To(BeNil())
+			Expect(math.Signbit(result)).To(BeTrue())
+		}
+	})
+
+	It("stops on canceled contexts", func() {
+		i := interpreter.NewInterpreter("a + 1")
+		i.EnableMemoization()
+		i.SetVar("a", 1)
+		Expect(i.GetResult()).To(Equal(2.0))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, errs := i.GetResultContext(ctx)
+		Expect(errs).To(Equal([]error{context.Canceled}))
+	})
+})
@@ -64,6 +64,9 @@ func (n *OptimizedNode) SetRight(r parser.INode) {
 	panic("") // @todo
 }
 
+// Constant returns the pre calculated value.
+func (n *OptimizedNode) Constant() float64 { return n.Value }
+
 // Calculate returns the calculated value if it is pre calculated.
 // Otherwise returns the result of the calculation visitor.
 func (n *OptimizedNode) Calculate(fn parser.CalcVisitor) (float64, error) {
@@ -82,9 +85,21 @@ func newOptimizedNode(value float64) *OptimizedNode {
 	}
 }
 
+// copyNode returns a copy of n with the given child nodes. This way the
+// original ast stays untouched.
+func copyNode(n parser.INode, left, right parser.INode) parser.INode {
+	return &parser.Node{
+		Type:       n.GetType(),
+		Value:      n.GetValue(),
+		LeftChild:  left,
+		RightChild: right,
+	}
+}
+
 // Optimize optimizes an ast.
 // Interprets all integer and decimal nodes.
-// Interprets all operations, if their child nodes can already be interpreted
+// Interprets all operations, if their child nodes can already be interpreted.
+// The given ast doesn't get modified.
 func Optimize(ast parser.IAST) (*OptimizedAST, error) {
 	if ast == nil {
 		return nil, nil
@@ -107,6 +122,10 @@ func optimizeNode(n parser.INode) (parser.INode, error) {
 		return optimizeOperator(n)
 	}
 
+	if parser.IsBinaryFunction(n) {
+		return optimizeBinaryFunction(n)
+	}
+
 	if parser.IsFunction(n) {
 		return optimizeFunction(n)
 	}
@@ -138,9 +157,7 @@ func optimizeOperator(n parser.INode) (parser.INode, error) {
 	}
 
 	if left.GetType() != parser.NDec || right.GetType() != parser.NDec {
-		n.SetLeft(left)
-		n.SetRight(right)
-		return n, nil
+		return copyNode(n, left, right), nil
 	}
 
 	var result float64
@@ -183,8 +200,7 @@ func optimizeFunction(n parser.INode) (parser.INode, error) {
 	}
 
 	if left.GetType() != parser.NDec {
-		n.SetLeft(left)
-		return n, nil
+		return copyNode(n, left, nil), nil
 	}
 
 	var result float64
@@ -196,3 +212,25 @@ func optimizeFunction(n parser.INode) (parser.INode, error) {
 
 	return newOptimizedNode(result), nil
 }
+
+// optimizeBinaryFunction recursively optimizes a function node with two
+// arguments.
+func optimizeBinaryFunction(n parser.INode) (parser.INode, error) {
+	left, right, err := getOptimizedNodeChilds(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if left.GetType() != parser.NDec || right.GetType() != parser.NDec {
+		return copyNode(n, left, right), nil
+	}
+
+	leftVal, _ := left.Calculate(nil)
+	rightVal, _ := right.Calculate(nil)
+	result, err := calculator.CalculateBinaryFunction(leftVal, rightVal, n.GetType())
+	if err != nil {
+		return nil, err
+	}
+
+	return newOptimizedNode(result), nil
+}
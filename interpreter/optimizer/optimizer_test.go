@@ -63,6 +63,36 @@ var _ = Describe("Optimizer with ast generated by parser", func() {
 					Value: 4.0,
 				},
 			}, nil),
+			Entry("oct becomes dec", "0o17", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 15.0,
+				},
+			}, nil),
+			Entry("scientific becomes dec", "1.5e-3", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 0.0015,
+				},
+			}, nil),
+			Entry("hex float becomes dec", "0x1.8p1", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 3.0,
+				},
+			}, nil),
+			Entry("separators get removed", "1_000_000", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 1000000.0,
+				},
+			}, nil),
+			Entry("leading dot becomes dec", ".5", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 0.5,
+				},
+			}, nil),
 		)
 
 		DescribeTable("operations get calculated", test,
@@ -115,6 +145,27 @@ var _ = Describe("Optimizer with ast generated by parser", func() {
 					Value: 1.0,
 				},
 			}, nil),
+			Entry("shift left", "1 << 4", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 16.0,
+				},
+			}, nil),
+			Entry("shift right", "-16 >> 2", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: -4.0,
+				},
+			}, nil),
+			Entry("unsigned shift right", "-1 >>> 56", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 255.0,
+				},
+			}, nil),
+			Entry("bitwise operator with decimal", "1.5 | 1", nil, calculator.ErrorNotAnInteger),
+			Entry("shift by negative count", "1 << -1", nil, calculator.ErrorOutOfDomain),
+			Entry("shift overflow", "1 << 63", nil, calculator.ErrorOverflow),
 		)
 
 		DescribeTable("functions get calculated", test,
@@ -142,6 +193,36 @@ var _ = Describe("Optimizer with ast generated by parser", func() {
 					Value: math.Tan(1),
 				},
 			}, nil),
+			Entry("factorial", "4!", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 24.0,
+				},
+			}, nil),
+			Entry("binom", "binom(4, 2)", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 6.0,
+				},
+			}, nil),
+			Entry("binom with variable", "binom(a, 2)", &optimizer.OptimizedAST{
+				Node: &parser.Node{
+					Type:      parser.NFnBinom,
+					LeftChild: &parser.Node{Type: parser.NVar, Value: "a"},
+					RightChild: &optimizer.OptimizedNode{
+						Type:  parser.NDec,
+						Value: 2.0,
+					},
+				},
+			}, nil),
+			Entry("domain error", "gcd(1.5, 2)", nil, calculator.ErrorNotAnInteger),
+			Entry("bitwise not", "~0xf0 & 0xff", &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{
+					Type:  parser.NDec,
+					Value: 15.0,
+				},
+			}, nil),
+			Entry("bitwise not of decimal", "~0.5", nil, calculator.ErrorNotAnInteger),
 		)
 	})
 
@@ -182,6 +263,9 @@ var _ = Describe("Optimizer with ast generated by parser", func() {
 			Entry("or", "|", parser.NOr),
 			Entry("xor", "^", parser.NXor),
 			Entry("and", "&", parser.NAnd),
+			Entry("shift left", "<<", parser.NShiftLeft),
+			Entry("shift right", ">>", parser.NShiftRight),
+			Entry("unsigned shift right", ">>>", parser.NUnsignedShiftRight),
 		)
 
 		DescribeTable("functions",
@@ -0,0 +1,273 @@
+// Package sheet contains a sheet of named formulas, that can reference each
+// other.
+//
+// Example:
+//  s := sheet.New()
+//  s.SetFormula("net", "gross - tax")
+//  s.SetFormula("tax", "gross * rate")
+//  s.SetVar("gross", 100)
+//  s.SetVar("rate", 0.2)
+//  s.Get("net") // Result: 80
+package sheet
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/parser"
+)
+
+// Errors, that can occur when working with a sheet
+var (
+	ErrorUnknownName      = errors.New("Error: Unknown name")
+	ErrorNameIsFormula    = errors.New("Error: Name is already used by a formula")
+	ErrorDependencyFailed = errors.New("Error: A dependency of the formula failed")
+	ErrorEmptyFormula     = errors.New("Error: Formula is empty")
+)
+
+// CycleError reports a cyclic dependency between formulas. Path starts and
+// ends with the same name.
+type CycleError struct {
+	Path []string
+}
+
+// Error returns the error message.
+func (e *CycleError) Error() string {
+	return "Error: Cyclic dependency: " + strings.Join(e.Path, " -> ")
+}
+
+// Sheet holds named formulas and variables. Formulas can reference variables
+// and other formulas by their name. They get evaluated in topological order
+// and only formulas, that depend on a changed name, get recalculated.
+// A sheet is not safe for concurrent use.
+type Sheet struct {
+	formulas   map[string]*interpreter.Expression
+	deps       map[string][]string
+	dependents map[string]map[string]bool
+
+	vars   map[string]bool
+	values interpreter.Env
+	errors map[string]error
+	dirty  map[string]bool
+}
+
+// New returns a new empty sheet.
+func New() *Sheet {
+	return &Sheet{
+		formulas:   make(map[string]*interpreter.Expression),
+		deps:       make(map[string][]string),
+		dependents: make(map[string]map[string]bool),
+		vars:       make(map[string]bool),
+		values:     make(interpreter.Env),
+		errors:     make(map[string]error),
+		dirty:      make(map[string]bool),
+	}
+}
+
+// SetFormula sets the formula with the given name. An existing formula or
+// variable with the same name gets replaced.
+// Returns the parser errors, ErrorEmptyFormula or a *CycleError, if the formula
+// would introduce a cyclic dependency. In that case the sheet stays unchanged.
+func (s *Sheet) SetFormula(name string, formula string) []error {
+	ast, errors := parser.Parse(formula)
+	if errors != nil {
+		return errors
+	}
+	if ast.Node == nil {
+		return []error{ErrorEmptyFormula}
+	}
+
+	deps := parser.Variables(&ast)
+	for _, dep := range deps {
+		if path := s.path(dep, name, make(map[string]bool)); path != nil {
+			return []error{&CycleError{Path: append([]string{name}, path...)}}
+		}
+	}
+
+	s.removeDeps(name)
+	delete(s.vars, name)
+
+	s.formulas[name] = interpreter.NewExpressionFromAST(&ast)
+	s.deps[name] = deps
+	for _, dep := range deps {
+		if s.dependents[dep] == nil {
+			s.dependents[dep] = make(map[string]bool)
+		}
+		s.dependents[dep][name] = true
+	}
+	s.markDirty(name)
+
+	return nil
+}
+
+// SetVar sets the value of a variable. All formulas, that depend on the
+// variable, get recalculated at the next call of Recalculate or Get.
+// Returns ErrorNameIsFormula, if there is a formula with the same name.
+func (s *Sheet) SetVar(name string, value float64) error {
+	if _, ok := s.formulas[name]; ok {
+		return ErrorNameIsFormula
+	}
+
+	s.vars[name] = true
+	s.values[name] = value
+	s.markDependentsDirty(name)
+
+	return nil
+}
+
+// Remove removes the formula or variable with the given name. Formulas, that
+// depend on it, fail from now on with interpreter.ErrorVariableNotDefined.
+func (s *Sheet) Remove(name string) {
+	s.removeDeps(name)
+	delete(s.formulas, name)
+	delete(s.vars, name)
+	delete(s.values, name)
+	delete(s.errors, name)
+	delete(s.dirty, name)
+	s.markDependentsDirty(name)
+}
+
+// Get returns the value of a formula or variable. Changed formulas get
+// recalculated first.
+// Returns the error of the formula, if its evaluation failed and
+// ErrorUnknownName, if there is neither a formula nor a variable with the
+// given name.
+func (s *Sheet) Get(name string) (float64, error) {
+	if _, ok := s.formulas[name]; !ok && !s.vars[name] {
+		return 0, ErrorUnknownName
+	}
+
+	s.Recalculate()
+
+	if err, ok := s.errors[name]; ok {
+		return 0, err
+	}
+
+	return s.values[name], nil
+}
+
+// Recalculate evaluates all changed formulas and their dependents in
+// topological order. Returns the names of the recalculated formulas.
+func (s *Sheet) Recalculate() []string {
+	if len(s.dirty) == 0 {
+		return nil
+	}
+
+	var recalculated []string
+	for _, name := range s.Order() {
+		if !s.dirty[name] {
+			continue
+		}
+
+		s.evaluate(name)
+		recalculated = append(recalculated, name)
+	}
+	s.dirty = make(map[string]bool)
+
+	return recalculated
+}
+
+// Order returns the names of all formulas in topological order. Each formula
+// comes after the formulas it depends on.
+func (s *Sheet) Order() []string {
+	names := make([]string, 0, len(s.formulas))
+	for name := range s.formulas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	visited := make(map[string]bool)
+	order := make([]string, 0, len(names))
+
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+
+		for _, dep := range s.deps[name] {
+			if _, ok := s.formulas[dep]; ok {
+				visit(dep)
+			}
+		}
+		order = append(order, name)
+	}
+	for _, name := range names {
+		visit(name)
+	}
+
+	return order
+}
+
+// evaluate evaluates a single formula. Its dependencies have to be evaluated
+// already.
+func (s *Sheet) evaluate(name string) {
+	delete(s.values, name)
+	delete(s.errors, name)
+
+	for _, dep := range s.deps[name] {
+		if _, ok := s.errors[dep]; ok {
+			s.errors[name] = ErrorDependencyFailed
+			return
+		}
+	}
+
+	value, err := s.formulas[name].Eval(s.values)
+	if err != nil {
+		s.errors[name] = err
+		return
+	}
+
+	s.values[name] = value
+}
+
+// path returns the path of formulas from one name to another, following the
+// dependencies. Returns nil, if to can't be reached from from. Names in visited
+// are skipped.
+func (s *Sheet) path(from string, to string, visited map[string]bool) []string {
+	if from == to {
+		return []string{to}
+	}
+	if visited[from] {
+		return nil
+	}
+	visited[from] = true
+
+	for _, dep := range s.deps[from] {
+		if path := s.path(dep, to, visited); path != nil {
+			return append([]string{from}, path...)
+		}
+	}
+
+	return nil
+}
+
+// removeDeps removes the dependencies of a formula from the graph.
+func (s *Sheet) removeDeps(name string) {
+	for _, dep := range s.deps[name] {
+		delete(s.dependents[dep], name)
+	}
+	delete(s.deps, name)
+}
+
+// markDirty marks a formula and all of its transitive dependents for
+// recalculation.
+func (s *Sheet) markDirty(name string) {
+	if s.dirty[name] {
+		return
+	}
+
+	s.dirty[name] = true
+	s.markDependentsDirty(name)
+}
+
+// markDependentsDirty marks all transitive dependents of a name for
+// recalculation.
+func (s *Sheet) markDependentsDirty(name string) {
+	for dependent := range s.dependents[name] {
+		s.markDirty(dependent)
+	}
+}
@@ -0,0 +1,154 @@
+package sheet_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/sheet"
+	"github.com/relnod/calcgo/parser"
+)
+
+func TestSheet(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sheet Suite")
+}
+
+// newPayroll returns a sheet with formulas referencing each other.
+func newPayroll() *sheet.Sheet {
+	s := sheet.New()
+	Expect(s.SetFormula("net", "gross - tax")).To(BeNil())
+	Expect(s.SetFormula("tax", "gross * rate")).To(BeNil())
+	Expect(s.SetFormula("bonus", "base * 2")).To(BeNil())
+	Expect(s.SetFormula("gross", "base + bonus")).To(BeNil())
+	Expect(s.SetVar("base", 100)).To(BeNil())
+	Expect(s.SetVar("rate", 0.5)).To(BeNil())
+
+	return s
+}
+
+var _ = Describe("Sheet", func() {
+	It("evaluates formulas referencing each other", func() {
+		s := newPayroll()
+
+		Expect(s.Get("net")).To(Equal(150.0))
+		Expect(s.Get("tax")).To(Equal(150.0))
+		Expect(s.Get("gross")).To(Equal(300.0))
+		Expect(s.Get("base")).To(Equal(100.0))
+	})
+
+	It("returns the formulas in topological order", func() {
+		s := newPayroll()
+
+		Expect(s.Order()).To(Equal([]string{"bonus", "gross", "tax", "net"}))
+	})
+
+	It("recalculates all formulas initially", func() {
+		s := newPayroll()
+
+		Expect(s.Recalculate()).To(Equal([]string{"bonus", "gross", "tax", "net"}))
+		Expect(s.Recalculate()).To(BeNil())
+	})
+
+	It("recalculates only dependents of a changed variable", func() {
+		s := newPayroll()
+		s.Recalculate()
+
+		Expect(s.SetVar("rate", 0.25)).To(BeNil())
+		Expect(s.Recalculate()).To(Equal([]string{"tax", "net"}))
+		Expect(s.Get("net")).To(Equal(225.0))
+
+		Expect(s.SetVar("base", 10)).To(BeNil())
+		Expect(s.Recalculate()).To(Equal([]string{"bonus", "gross", "tax", "net"}))
+		Expect(s.Get("net")).To(Equal(22.5))
+	})
+
+	It("recalculates only dependents of a changed formula", func() {
+		s := newPayroll()
+		s.Recalculate()
+
+		Expect(s.SetFormula("tax", "10")).To(BeNil())
+		Expect(s.Recalculate()).To(Equal([]string{"tax", "net"}))
+		Expect(s.Get("net")).To(Equal(290.0))
+	})
+
+	It("replaces a variable with a formula", func() {
+		s := newPayroll()
+		s.Recalculate()
+
+		Expect(s.SetFormula("rate", "1 / 10")).To(BeNil())
+		Expect(s.Recalculate()).To(Equal([]string{"rate", "tax", "net"}))
+		Expect(s.Get("net")).To(Equal(270.0))
+	})
+
+	It("doesn't replace a formula with a variable", func() {
+		s := newPayroll()
+
+		Expect(s.SetVar("tax", 1)).To(Equal(sheet.ErrorNameIsFormula))
+	})
+
+	It("detects cycles", func() {
+		s := newPayroll()
+
+		errs := s.SetFormula("base", "net * 2")
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(Equal(&sheet.CycleError{
+			Path: []string{"base", "net", "gross", "base"},
+		}))
+		Expect(errs[0].Error()).To(Equal("Error: Cyclic dependency: base -> net -> gross -> base"))
+
+		Expect(s.Get("net")).To(Equal(150.0))
+	})
+
+	It("detects self references", func() {
+		s := sheet.New()
+
+		Expect(s.SetFormula("a", "a + 1")).To(Equal([]error{
+			&sheet.CycleError{Path: []string{"a", "a"}},
+		}))
+	})
+
+	It("returns parser errors", func() {
+		s := sheet.New()
+
+		Expect(s.SetFormula("a", "1 + $")).To(Equal([]error{parser.ErrorExpectedNumberOrVariable}))
+		Expect(s.Get("a")).Error().To(Equal(sheet.ErrorUnknownName))
+	})
+
+	It("rejects empty formulas", func() {
+		s := sheet.New()
+		Expect(s.SetFormula("a", "1")).To(BeNil())
+
+		Expect(s.SetFormula("a", "")).To(Equal([]error{sheet.ErrorEmptyFormula}))
+		Expect(s.SetFormula("b", "  ")).To(Equal([]error{sheet.ErrorEmptyFormula}))
+		Expect(s.Get("a")).To(Equal(1.0))
+		Expect(s.Get("b")).Error().To(Equal(sheet.ErrorUnknownName))
+	})
+
+	It("reports undefined variables and failed dependencies", func() {
+		s := sheet.New()
+		Expect(s.SetFormula("a", "b + 1")).To(BeNil())
+		Expect(s.SetFormula("c", "a * 2")).To(BeNil())
+
+		Expect(s.Get("a")).Error().To(Equal(interpreter.ErrorVariableNotDefined))
+		Expect(s.Get("c")).Error().To(Equal(sheet.ErrorDependencyFailed))
+
+		Expect(s.SetVar("b", 1)).To(BeNil())
+		Expect(s.Get("c")).To(Equal(4.0))
+	})
+
+	It("removes formulas", func() {
+		s := newPayroll()
+		s.Recalculate()
+
+		s.Remove("bonus")
+		Expect(s.Recalculate()).To(Equal([]string{"gross", "tax", "net"}))
+		Expect(s.Get("bonus")).Error().To(Equal(sheet.ErrorUnknownName))
+		Expect(s.Get("gross")).Error().To(Equal(interpreter.ErrorVariableNotDefined))
+
+		Expect(s.SetVar("bonus", 0)).To(BeNil())
+		Expect(s.Get("net")).To(Equal(50.0))
+	})
+})
@@ -0,0 +1,249 @@
+// Package simplifier contains a rule based algebraic simplifier.
+//
+// The simplifier converts an ast into a sum of terms, where each term is a
+// product of factors with a coefficient. This way the following rules get
+// applied:
+//  - constant folding (1 + 2 => 3)
+//  - identities (x * 1 => x, x + 0 => x, 0 * x => 0, x - x => 0)
+//  - reassociation of constants ((2 * x) * 3 => 6 * x, 1 + x + 2 => x + 3)
+//  - collection of like terms (x + 2 * x => 3 * x)
+//  - power rules for equal factors (x * y / x => y)
+//
+// Cancelling factors assumes, that they are not zero. E.g. x / x becomes 1,
+// even though it isn't defined for x = 0.
+package simplifier
+
+import (
+	"errors"
+
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/parser"
+)
+
+// Errors, that can occur during simplifying
+var (
+	ErrorMissingLeftChild        = errors.New("Error: Missing left child of node")
+	ErrorMissingRightChild       = errors.New("Error: Missing right child of node")
+	ErrorMissingFunctionArgument = errors.New("Error: Missing function argument")
+	ErrorInvalidNodeType         = errors.New("Error: Invalid node type")
+)
+
+// Simplify returns a new simplified ast.
+// The given ast doesn't get modified.
+func Simplify(ast parser.IAST) (*parser.AST, error) {
+	if parser.IsEmpty(ast) {
+		return &parser.AST{}, nil
+	}
+
+	s, err := toSum(ast.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	return &parser.AST{Node: s.node().(*parser.Node)}, nil
+}
+
+// SimplifyString parses a string and returns the simplified ast.
+func SimplifyString(str string) (*parser.AST, []error) {
+	ast, errors := parser.Parse(str)
+	if errors != nil {
+		return nil, errors
+	}
+
+	s, err := Simplify(&ast)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return s, nil
+}
+
+// toSum recursively converts a node into a sum.
+func toSum(n parser.INode) (sum, error) {
+	if parser.IsLiteral(n) {
+		return literalToSum(n), nil
+	}
+
+	if parser.IsOperator(n) {
+		return operatorToSum(n)
+	}
+
+	if parser.IsBinaryFunction(n) {
+		return binaryFunctionToSum(n)
+	}
+
+	if parser.IsFunction(n) {
+		return functionToSum(n)
+	}
+
+	return sum{}, ErrorInvalidNodeType
+}
+
+// literalToSum converts a number or variable node into a sum.
+// Invalid numbers are kept as they are.
+func literalToSum(n parser.INode) sum {
+	if value, ok := numberValue(n); ok {
+		return newConstant(value)
+	}
+
+	return newAtom(copyTree(n))
+}
+
+// operatorToSum converts an operator node into a sum.
+func operatorToSum(n parser.INode) (sum, error) {
+	if n.Left() == nil {
+		return sum{}, ErrorMissingLeftChild
+	}
+	if n.Right() == nil {
+		return sum{}, ErrorMissingRightChild
+	}
+
+	l, err := toSum(n.Left())
+	if err != nil {
+		return sum{}, err
+	}
+	r, err := toSum(n.Right())
+	if err != nil {
+		return sum{}, err
+	}
+
+	switch n.GetType() {
+	case parser.NAdd:
+		return l.add(r, 1), nil
+	case parser.NSub:
+		return l.add(r, -1), nil
+	case parser.NMult:
+		if l.isConstant() {
+			return r.scale(l.constant), nil
+		}
+		if r.isConstant() {
+			return l.scale(r.constant), nil
+		}
+		return fromTerm(l.asTerm().multiply(r.asTerm(), 1)), nil
+	case parser.NDiv:
+		if r.isConstant() && r.constant != 0 {
+			return l.scale(1 / r.constant), nil
+		}
+		if r.isMonomial() {
+			return divideSum(l, r.terms[0]), nil
+		}
+		if !r.isConstant() {
+			return fromTerm(l.asTerm().multiply(r.asTerm(), -1)), nil
+		}
+	}
+
+	if l.isConstant() && r.isConstant() {
+		result, err := calculator.CalculateOperator(l.constant, r.constant, n.GetType())
+		if err == nil {
+			return newConstant(result), nil
+		}
+	}
+
+	return newAtom(operator(n.GetType(), l.node(), r.node())), nil
+}
+
+// divideSum divides every term and the constant of s by t.
+func divideSum(s sum, t term) sum {
+	result := fromTerm(term{coeff: s.constant}.multiply(t, -1))
+	for _, t2 := range s.terms {
+		result = result.add(fromTerm(t2.multiply(t, -1)), 1)
+	}
+
+	return result
+}
+
+// functionToSum converts a function node into a sum. Functions with a constant
+// argument get calculated.
+func functionToSum(n parser.INode) (sum, error) {
+	if n.Left() == nil {
+		return sum{}, ErrorMissingFunctionArgument
+	}
+
+	arg, err := toSum(n.Left())
+	if err != nil {
+		return sum{}, err
+	}
+
+	if arg.isConstant() {
+		result, err := calculator.CalculateFunction(arg.constant, n.GetType())
+		if err == nil {
+			return newConstant(result), nil
+		}
+	}
+
+	return newAtom(&parser.Node{Type: n.GetType(), LeftChild: arg.node()}), nil
+}
+
+// binaryFunctionToSum converts a function node with two arguments into a sum.
+// Functions with constant arguments get calculated.
+func binaryFunctionToSum(n parser.INode) (sum, error) {
+	if n.Left() == nil || n.Right() == nil {
+		return sum{}, ErrorMissingFunctionArgument
+	}
+
+	l, err := toSum(n.Left())
+	if err != nil {
+		return sum{}, err
+	}
+	r, err := toSum(n.Right())
+	if err != nil {
+		return sum{}, err
+	}
+
+	if l.isConstant() && r.isConstant() {
+		result, err := calculator.CalculateBinaryFunction(l.constant, r.constant, n.GetType())
+		if err == nil {
+			return newConstant(result), nil
+		}
+	}
+
+	return newAtom(&parser.Node{Type: n.GetType(), LeftChild: l.node(), RightChild: r.node()}), nil
+}
+
+// numberValue returns the value of a number node. The second return value is
+// false, if n is not a valid number.
+func numberValue(n parser.INode) (float64, bool) {
+	if n.GetType() == parser.NVar {
+		return 0, false
+	}
+
+	if _, ok := n.(*parser.Node); !ok {
+		value, err := n.Calculate(nil)
+		return value, err == nil
+	}
+
+	value, err := calculator.ConvertLiteral(n.GetValue(), n.GetType())
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
+
+// operator returns a new operator node.
+func operator(t parser.NodeType, l, r parser.INode) *parser.Node {
+	return &parser.Node{Type: t, LeftChild: l, RightChild: r}
+}
+
+// copyTree returns a deep copy of a tree.
+func copyTree(n parser.INode) parser.INode {
+	if n == nil {
+		return nil
+	}
+
+	node, ok := n.(*parser.Node)
+	if !ok {
+		value, _ := n.Calculate(nil)
+		return newNumber(value)
+	}
+	if node == nil {
+		return nil
+	}
+
+	return &parser.Node{
+		Type:       node.Type,
+		Value:      node.Value,
+		LeftChild:  copyTree(node.LeftChild),
+		RightChild: copyTree(node.RightChild),
+	}
+}
@@ -0,0 +1,171 @@
+package simplifier_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/interpreter/simplifier"
+	"github.com/relnod/calcgo/parser"
+)
+
+func TestSimplifier(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Simplifier Suite")
+}
+
+// points are the variable values, that are used to compare the original and
+// the simplified expression.
+var points = []interpreter.Env{
+	{"x": 0.5, "y": 2, "z": -3},
+	{"x": 1.7, "y": -0.25, "z": 4},
+	{"x": -2.3, "y": 3.5, "z": 0.75},
+}
+
+// countNodes returns the number of nodes of a tree.
+func countNodes(n parser.INode) int {
+	if parser.IsNil(n) {
+		return 0
+	}
+
+	return 1 + countNodes(n.Left()) + countNodes(n.Right())
+}
+
+var _ = Describe("Simplify()", func() {
+	DescribeTable("simplifies to",
+		func(in string, expected string) {
+			ast, errs := simplifier.SimplifyString(in)
+			Expect(errs).To(BeNil())
+			expAST, errs := parser.Parse(expected)
+			Expect(errs).To(BeNil())
+			Expect(ast).To(Equal(&expAST))
+		},
+		Entry("constant folding", "1 + 2 * 3", "7"),
+		Entry("x * 1", "x * 1", "x"),
+		Entry("1 * x", "1 * x", "x"),
+		Entry("x + 0", "x + 0", "x"),
+		Entry("0 + x", "0 + x", "x"),
+		Entry("x - 0", "x - 0", "x"),
+		Entry("0 * x", "0 * x", "0"),
+		Entry("x * 0", "x * 0", "0"),
+		Entry("x / 1", "x / 1", "x"),
+		Entry("x - x", "x - x", "0"),
+		Entry("(2 * x) * 3", "(2 * x) * 3", "6 * x"),
+		Entry("2 * (3 * x)", "2 * (3 * x)", "6 * x"),
+		Entry("constants across additions", "1 + x + 2", "x + 3"),
+		Entry("like terms", "x + y + 2 * x - y", "3 * x"),
+		Entry("like products", "x * y + y * x", "2 * x * y"),
+		Entry("negative coefficient", "x - 3 * y", "x - 3 * y"),
+		Entry("negative first term", "0 - x", "-1 * x"),
+		Entry("equal factors", "x * x * x", "x * x * x"),
+		Entry("cancelling factors", "x * y / x", "y"),
+		Entry("cancelling powers", "x * x * y / (x * y)", "x"),
+		Entry("division by constant", "x / 4", "0.25 * x"),
+		Entry("division by monomial", "(x * y + y) / y", "x + 1"),
+		Entry("division by sum", "x / (x + 1)", "x / (x + 1)"),
+		Entry("cancelling sums", "(x + 1) * y / (x + 1)", "y"),
+		Entry("function argument", "sin(x + x)", "sin(2 * x)"),
+		Entry("constant function", "sqrt(16) * x", "4 * x"),
+		Entry("like functions", "sin(x) + 2 * sin(x)", "3 * sin(x)"),
+		Entry("function with two arguments", "binom(x + x, 1 + 1)", "binom(2 * x, 2)"),
+		Entry("constant function with two arguments", "gcd(12, 18) * x!", "6 * x!"),
+		Entry("function error is kept", "mod(x, 0) + mod(1, 0)", "mod(x, 0) + mod(1, 0)"),
+		Entry("other operators", "(x % 2) * 1", "x % 2"),
+		Entry("other constant operators", "5 % 3 + x", "x + 2"),
+		Entry("division by zero is kept", "x / 0", "x / 0"),
+	)
+
+	DescribeTable("is numerically equivalent",
+		func(in string) {
+			ast, errs := parser.Parse(in)
+			Expect(errs).To(BeNil())
+
+			s, err := simplifier.Simplify(&ast)
+			Expect(err).To(BeNil())
+			Expect(countNodes(s.Node)).To(BeNumerically("<=", countNodes(ast.Node)))
+
+			for _, env := range points {
+				expected, err := interpreter.NewExpressionFromAST(&ast).Eval(env)
+				Expect(err).To(BeNil())
+				result, err := interpreter.NewExpressionFromAST(s).Eval(env)
+				Expect(err).To(BeNil())
+				Ω(result).Should(BeNumerically("~", expected, 1e-9))
+			}
+		},
+		Entry("1", "x * 1 + 0 * y - (z - z)"),
+		Entry("2", "(2 * x) * 3 + x * 4 - y / 2"),
+		Entry("3", "x * y * z / (y * x) + 1 - 1"),
+		Entry("4", "sin(x) * cos(y) + sin(x) * cos(y) * 2"),
+		Entry("5", "(x + y) * (x + y) / (x + y)"),
+		Entry("6", "1 / x + 2 / x - 3 / x + z"),
+		Entry("7", "sqrt(x * x) + tan(y / 2 * 2)"),
+		Entry("8", "(x - y) * 2 - 2 * x + 2 * y + z"),
+		Entry("9", "x * x * x / (x * x) - x + 5 % 3"),
+	)
+
+	It("works with optimized asts", func() {
+		ast, errs := parser.Parse("(1 + 2) * x * 2")
+		Expect(errs).To(BeNil())
+		oast, err := optimizer.Optimize(&ast)
+		Expect(err).To(BeNil())
+
+		s, err := simplifier.Simplify(oast)
+		Expect(err).To(BeNil())
+		expected, _ := parser.Parse("6 * x")
+		Expect(s).To(Equal(&expected))
+	})
+
+	It("returns an empty ast for empty input", func() {
+		s, errs := simplifier.SimplifyString("")
+		Expect(errs).To(BeNil())
+		Expect(s).To(Equal(&parser.AST{}))
+
+		s, err := simplifier.Simplify(&parser.AST{})
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal(&parser.AST{}))
+	})
+
+	It("doesn't modify the given ast", func() {
+		ast, errs := parser.Parse("x * 1 + sin(x - x)")
+		Expect(errs).To(BeNil())
+		expected, _ := parser.Parse("x * 1 + sin(x - x)")
+
+		_, err := simplifier.Simplify(&ast)
+		Expect(err).To(BeNil())
+		Expect(ast).To(Equal(expected))
+	})
+
+	DescribeTable("errors",
+		func(ast *parser.AST, expErr error) {
+			s, err := simplifier.Simplify(ast)
+			Expect(s).To(BeNil())
+			Expect(err).To(Equal(expErr))
+		},
+		Entry("missing left child", &parser.AST{Node: &parser.Node{
+			Type:       parser.NAdd,
+			RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+		}}, simplifier.ErrorMissingLeftChild),
+		Entry("missing right child", &parser.AST{Node: &parser.Node{
+			Type:      parser.NAdd,
+			LeftChild: &parser.Node{Type: parser.NInt, Value: "2"},
+		}}, simplifier.ErrorMissingRightChild),
+		Entry("missing function argument", &parser.AST{Node: &parser.Node{
+			Type: parser.NFnSin,
+		}}, simplifier.ErrorMissingFunctionArgument),
+		Entry("invalid node type", &parser.AST{Node: &parser.Node{
+			Type: 3000,
+		}}, simplifier.ErrorInvalidNodeType),
+	)
+
+	It("keeps invalid numbers", func() {
+		s, err := simplifier.Simplify(&parser.AST{Node: &parser.Node{Type: parser.NInt, Value: "a"}})
+		Expect(err).To(BeNil())
+		_, err = interpreter.InterpretAST(s)
+		Expect(err).To(Equal(calculator.ErrorInvalidInteger))
+	})
+})
@@ -0,0 +1,276 @@
+package simplifier
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/relnod/calcgo/parser"
+)
+
+// factor is a node raised to an integer exponent.
+type factor struct {
+	key  string
+	node parser.INode
+	exp  int
+}
+
+// term is a product of factors with a coefficient.
+type term struct {
+	coeff   float64
+	factors []factor
+}
+
+// sum is a sum of terms and a constant.
+type sum struct {
+	terms    []term
+	constant float64
+}
+
+// newConstant returns a sum, that only consists of a constant.
+func newConstant(c float64) sum {
+	return sum{constant: c}
+}
+
+// newAtom returns a sum, that only consists of the node n.
+func newAtom(n parser.INode) sum {
+	return sum{terms: []term{{
+		coeff:   1,
+		factors: []factor{{key: nodeKey(n), node: n, exp: 1}},
+	}}}
+}
+
+// key returns a key of the factors of t. Terms with the same key are like
+// terms.
+func (t term) key() string {
+	keys := make([]string, len(t.factors))
+	for i, f := range t.factors {
+		keys[i] = f.key + "^" + strconv.Itoa(f.exp)
+	}
+	sort.Strings(keys)
+
+	return strings.Join(keys, "*")
+}
+
+// isConstant returns true if s doesn't contain any terms.
+func (s sum) isConstant() bool {
+	return len(s.terms) == 0
+}
+
+// isMonomial returns true if s consists of a single term.
+func (s sum) isMonomial() bool {
+	return len(s.terms) == 1 && s.constant == 0
+}
+
+// add returns s + sign * s2. Like terms get collected.
+func (s sum) add(s2 sum, sign float64) sum {
+	result := sum{
+		terms:    append([]term(nil), s.terms...),
+		constant: s.constant + sign*s2.constant,
+	}
+
+	for _, t := range s2.terms {
+		t.coeff *= sign
+		result = result.addTerm(t)
+	}
+
+	return result
+}
+
+// addTerm adds a single term to s. Terms, whose coefficient becomes 0 get
+// removed.
+func (s sum) addTerm(t term) sum {
+	key := t.key()
+	for i, t2 := range s.terms {
+		if t2.key() != key {
+			continue
+		}
+
+		coeff := t2.coeff + t.coeff
+		if coeff == 0 {
+			s.terms = append(s.terms[:i:i], s.terms[i+1:]...)
+			return s
+		}
+		s.terms[i].coeff = coeff
+		return s
+	}
+
+	if t.coeff != 0 {
+		s.terms = append(s.terms, t)
+	}
+
+	return s
+}
+
+// scale returns s * c.
+func (s sum) scale(c float64) sum {
+	if c == 0 {
+		return newConstant(0)
+	}
+
+	result := sum{constant: s.constant * c}
+	for _, t := range s.terms {
+		t.coeff *= c
+		result.terms = append(result.terms, t)
+	}
+
+	return result
+}
+
+// asTerm converts s to a single term. Sums, that consist of multiple terms
+// become a single factor.
+func (s sum) asTerm() term {
+	if s.isConstant() {
+		return term{coeff: s.constant}
+	}
+	if s.isMonomial() {
+		return s.terms[0]
+	}
+
+	n := s.node()
+	return term{coeff: 1, factors: []factor{{key: nodeKey(n), node: n, exp: 1}}}
+}
+
+// fromTerm returns a sum, that consists of a single term.
+func fromTerm(t term) sum {
+	if t.coeff == 0 {
+		return newConstant(0)
+	}
+	if len(t.factors) == 0 {
+		return newConstant(t.coeff)
+	}
+
+	return sum{terms: []term{t}}
+}
+
+// multiply returns t * t2^sign, where sign is either 1 or -1. The exponents of
+// equal factors get added.
+func (t term) multiply(t2 term, sign int) term {
+	result := term{coeff: t.coeff}
+	if sign > 0 {
+		result.coeff *= t2.coeff
+	} else {
+		result.coeff /= t2.coeff
+	}
+	result.factors = append(result.factors, t.factors...)
+
+	for _, f := range t2.factors {
+		f.exp *= sign
+		found := false
+		for i, f2 := range result.factors {
+			if f2.key == f.key {
+				result.factors[i].exp += f.exp
+				found = true
+				break
+			}
+		}
+		if !found {
+			result.factors = append(result.factors, f)
+		}
+	}
+
+	factors := result.factors[:0:0]
+	for _, f := range result.factors {
+		if f.exp != 0 {
+			factors = append(factors, f)
+		}
+	}
+	result.factors = factors
+
+	return result
+}
+
+// node converts s back into a tree.
+func (s sum) node() parser.INode {
+	var n parser.INode
+
+	for _, t := range s.terms {
+		if n == nil {
+			n = t.node(t.coeff)
+			continue
+		}
+
+		if t.coeff < 0 {
+			n = operator(parser.NSub, n, t.node(-t.coeff))
+		} else {
+			n = operator(parser.NAdd, n, t.node(t.coeff))
+		}
+	}
+
+	switch {
+	case n == nil:
+		return newNumber(s.constant)
+	case s.constant > 0:
+		return operator(parser.NAdd, n, newNumber(s.constant))
+	case s.constant < 0:
+		return operator(parser.NSub, n, newNumber(-s.constant))
+	}
+
+	return n
+}
+
+// node converts t with the given coefficient back into a tree. Factors with
+// negative exponents become the divisor.
+func (t term) node(coeff float64) parser.INode {
+	var numerator, denominator parser.INode
+	if coeff != 1 {
+		numerator = newNumber(coeff)
+	}
+
+	for _, f := range t.factors {
+		for i := 0; i < abs(f.exp); i++ {
+			if f.exp > 0 {
+				numerator = multiply(numerator, copyTree(f.node))
+			} else {
+				denominator = multiply(denominator, copyTree(f.node))
+			}
+		}
+	}
+
+	if numerator == nil {
+		numerator = newNumber(1)
+	}
+	if denominator == nil {
+		return numerator
+	}
+
+	return operator(parser.NDiv, numerator, denominator)
+}
+
+// multiply returns l * r. If l is nil, r gets returned.
+func multiply(l, r parser.INode) parser.INode {
+	if l == nil {
+		return r
+	}
+
+	return operator(parser.NMult, l, r)
+}
+
+// nodeKey returns a string, that identifies the tree n.
+func nodeKey(n parser.INode) string {
+	if n == nil {
+		return ""
+	}
+
+	return strconv.Itoa(int(n.GetType())) + "(" + n.GetValue() + "," +
+		nodeKey(n.Left()) + "," + nodeKey(n.Right()) + ")"
+}
+
+// abs returns the absolute value of i.
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+
+	return i
+}
+
+// newNumber returns a new integer or decimal node holding value.
+func newNumber(value float64) *parser.Node {
+	if value == math.Trunc(value) && math.Abs(value) < 1e15 {
+		return &parser.Node{Type: parser.NInt, Value: strconv.FormatInt(int64(value), 10)}
+	}
+
+	return &parser.Node{Type: parser.NDec, Value: strconv.FormatFloat(value, 'f', -1, 64)}
+}
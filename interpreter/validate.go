@@ -0,0 +1,87 @@
+package interpreter
+
+import (
+	"strconv"
+
+	"github.com/relnod/calcgo/lexer"
+	"github.com/relnod/calcgo/parser"
+	"github.com/relnod/calcgo/token"
+)
+
+// UndefinedVariableError reports a variable, that was not defined. Start and
+// End are the byte offsets of the variable in the expression. Line and Column
+// are the position of Start, the column is counted in runes. The offsets are
+// -1 and the line and column are 0, if the interpreter was initialized with an
+// ast.
+type UndefinedVariableError struct {
+	Name   string
+	Start  int
+	End    int
+	Line   int
+	Column int
+}
+
+// Error returns the error message.
+func (e *UndefinedVariableError) Error() string {
+	msg := ErrorVariableNotDefined.Error() + ": '" + e.Name + "'"
+	if e.Start < 0 {
+		return msg
+	}
+
+	return msg + " at " + strconv.Itoa(e.Start) + "-" + strconv.Itoa(e.End)
+}
+
+// Unwrap returns ErrorVariableNotDefined.
+func (e *UndefinedVariableError) Unwrap() error {
+	return ErrorVariableNotDefined
+}
+
+// Validate checks, that all variables of the expression are defined. Instead
+// of stopping at the first undefined variable, like GetResult does, an
+// *UndefinedVariableError gets returned for every occurrence of an undefined
+// variable. Parser errors are returned as they are.
+//
+// Example:
+//  i := interpreter.NewInterpreter("a + b * a")
+//  i.SetVar("b", 1)
+//  i.Validate() // Result: undefined variable 'a' at 0-1 and at 8-9
+func (i *Interpreter) Validate() []error {
+	if i.str == "" && i.ast == nil {
+		return nil
+	}
+
+	if errors := i.parse(); errors != nil {
+		return errors
+	}
+
+	var errors []error
+	if i.str == "" {
+		for _, name := range parser.Variables(i.ast) {
+			if _, ok := i.vars[name]; !ok {
+				errors = append(errors, &UndefinedVariableError{Name: name, Start: -1, End: -1})
+			}
+		}
+
+		return errors
+	}
+
+	l := lexer.NewLexerFromString(i.str)
+	for t := l.Read(); t.Type != token.EOF; t = l.Read() {
+		if t.Type != token.Var {
+			continue
+		}
+
+		if _, ok := i.vars[t.Value]; !ok {
+			start, _ := l.Position()
+			errors = append(errors, &UndefinedVariableError{
+				Name:   t.Value,
+				Start:  t.Start,
+				End:    t.End,
+				Line:   start.Line,
+				Column: start.Column,
+			})
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,83 @@
+package interpreter_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/parser"
+)
+
+var _ = Describe("Validate()", func() {
+	DescribeTable("reports undefined variables",
+		func(str string, vars []string, expErrs []error) {
+			i := interpreter.NewInterpreter(str)
+			for _, name := range vars {
+				i.SetVar(name, 1)
+			}
+
+			errs := i.Validate()
+			if expErrs != nil {
+				Expect(errs).To(Equal(expErrs))
+			} else {
+				Expect(errs).To(BeNil())
+			}
+		},
+		Entry("empty", "", nil, nil),
+		Entry("no variables", "1 + 2", nil, nil),
+		Entry("all defined", "a + b", []string{"a", "b"}, nil),
+		Entry("single", "1 + a", nil, []error{
+			&interpreter.UndefinedVariableError{Name: "a", Start: 4, End: 5, Line: 1, Column: 5},
+		}),
+		Entry("all at once", "ab + sqrt(c) * ab", []string{"x"}, []error{
+			&interpreter.UndefinedVariableError{Name: "ab", Start: 0, End: 2, Line: 1, Column: 1},
+			&interpreter.UndefinedVariableError{Name: "c", Start: 10, End: 11, Line: 1, Column: 11},
+			&interpreter.UndefinedVariableError{Name: "ab", Start: 15, End: 17, Line: 1, Column: 16},
+		}),
+		Entry("partially defined", "a * b - c", []string{"b"}, []error{
+			&interpreter.UndefinedVariableError{Name: "a", Start: 0, End: 1, Line: 1, Column: 1},
+			&interpreter.UndefinedVariableError{Name: "c", Start: 8, End: 9, Line: 1, Column: 9},
+		}),
+		Entry("multiple lines and runes", "α +\n\tΔt * β", []string{"β"}, []error{
+			&interpreter.UndefinedVariableError{Name: "α", Start: 0, End: 2, Line: 1, Column: 1},
+			&interpreter.UndefinedVariableError{Name: "Δt", Start: 6, End: 9, Line: 2, Column: 2},
+		}),
+		Entry("parser error", "1 + $", nil, []error{parser.ErrorExpectedNumberOrVariable}),
+	)
+
+	It("reports undefined variables of an ast without positions", func() {
+		ast, errs := parser.Parse("b + a * b")
+		Expect(errs).To(BeNil())
+
+		i := interpreter.NewInterpreterFromAST(&ast)
+		Expect(i.Validate()).To(Equal([]error{
+			&interpreter.UndefinedVariableError{Name: "a", Start: -1, End: -1},
+			&interpreter.UndefinedVariableError{Name: "b", Start: -1, End: -1},
+		}))
+
+		i.SetVar("a", 1)
+		i.SetVar("b", 2)
+		Expect(i.Validate()).To(BeNil())
+	})
+
+	It("can be used before GetResult()", func() {
+		i := interpreter.NewInterpreter("a + 1")
+		Expect(i.Validate()).To(HaveLen(1))
+
+		i.SetVar("a", 2)
+		Expect(i.Validate()).To(BeNil())
+		Expect(i.GetResult()).To(Equal(3.0))
+	})
+
+	It("wraps ErrorVariableNotDefined", func() {
+		err := &interpreter.UndefinedVariableError{Name: "a", Start: 4, End: 5, Line: 1, Column: 5}
+		Expect(errors.Is(err, interpreter.ErrorVariableNotDefined)).To(BeTrue())
+		Expect(err.Error()).To(Equal("Error: A variable was not defined: 'a' at 4-5"))
+
+		err = &interpreter.UndefinedVariableError{Name: "a", Start: -1, End: -1}
+		Expect(err.Error()).To(Equal("Error: A variable was not defined: 'a'"))
+	})
+})
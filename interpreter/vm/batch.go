@@ -0,0 +1,110 @@
+package vm
+
+import (
+	"errors"
+	"sync"
+)
+
+// Errors, that can occur during batch evaluation
+var (
+	ErrorColumnLength = errors.New("Error: Column length does not match the number of rows")
+)
+
+// EvalBatch evaluates the program once for every row of the given columns and
+// writes the results to out. Each variable of the program is read from the
+// column with the same name. All columns need to have the same length as out.
+//
+// The evaluation doesn't allocate per row. The first error that occurs stops
+// the evaluation.
+func (p *Program) EvalBatch(columns map[string][]float64, out []float64) error {
+	cols, err := p.batchColumns(columns, len(out))
+	if err != nil {
+		return err
+	}
+
+	return p.evalRows(cols, out, 0, len(out))
+}
+
+// EvalBatchParallel behaves like EvalBatch, but splits the rows into the given
+// number of shards, which get evaluated in their own go routines.
+func (p *Program) EvalBatchParallel(columns map[string][]float64, out []float64, shards int) error {
+	cols, err := p.batchColumns(columns, len(out))
+	if err != nil {
+		return err
+	}
+
+	if shards < 1 {
+		shards = 1
+	}
+	if shards > len(out) {
+		shards = len(out)
+	}
+	if shards <= 1 {
+		return p.evalRows(cols, out, 0, len(out))
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, shards)
+	size := (len(out) + shards - 1) / shards
+
+	for s := 0; s < shards; s++ {
+		start := s * size
+		end := start + size
+		if end > len(out) {
+			end = len(out)
+		}
+
+		wg.Add(1)
+		go func(s, start, end int) {
+			defer wg.Done()
+			errs[s] = p.evalRows(cols, out, start, end)
+		}(s, start, end)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batchColumns returns the columns in the order of the program variables.
+func (p *Program) batchColumns(columns map[string][]float64, rows int) ([][]float64, error) {
+	cols := make([][]float64, len(p.Vars))
+	for i, name := range p.Vars {
+		col, ok := columns[name]
+		if !ok {
+			return nil, ErrorVariableNotDefined
+		}
+		if len(col) != rows {
+			return nil, ErrorColumnLength
+		}
+		cols[i] = col
+	}
+
+	return cols, nil
+}
+
+// evalRows evaluates the rows in the range [start, end).
+func (p *Program) evalRows(cols [][]float64, out []float64, start, end int) error {
+	stack := make([]float64, p.stackSize)
+	vars := make([]float64, len(cols))
+
+	for row := start; row < end; row++ {
+		for i, col := range cols {
+			vars[i] = col[row]
+		}
+
+		result, err := p.exec(stack, vars)
+		if err != nil {
+			return err
+		}
+		out[row] = result
+	}
+
+	return nil
+}
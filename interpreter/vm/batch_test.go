@@ -0,0 +1,100 @@
+package vm_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/interpreter/vm"
+	"github.com/relnod/calcgo/parser"
+)
+
+var _ = Describe("EvalBatch()", func() {
+	compile := func(str string) *vm.Program {
+		ast, errs := parser.Parse(str)
+		Expect(errs).To(BeNil())
+		p, err := vm.CompileOptimized(&ast)
+		Expect(err).To(BeNil())
+		return p
+	}
+
+	columns := func(rows int) map[string][]float64 {
+		a := make([]float64, rows)
+		b := make([]float64, rows)
+		for i := range a {
+			a[i] = float64(i)
+			b[i] = float64(i%7) + 1
+		}
+		return map[string][]float64{"a": a, "b": b}
+	}
+
+	It("behaves the same as the interpreter", func() {
+		str := "(a + (2 * 3)) / b - sqrt(16)"
+		cols := columns(100)
+		out := make([]float64, 100)
+
+		Expect(compile(str).EvalBatch(cols, out)).To(Succeed())
+
+		for row := range out {
+			i := interpreter.NewInterpreter(str)
+			i.SetVar("a", cols["a"][row])
+			i.SetVar("b", cols["b"][row])
+			expected, errs := i.GetResult()
+			Expect(errs).To(BeNil())
+			Ω(out[row]).Should(BeNumerically("==", expected))
+		}
+	})
+
+	It("folds constant sub-trees", func() {
+		p := compile("a + (2 * 3) + sqrt(16)")
+		Expect(p.Consts).To(Equal([]float64{6, 4}))
+	})
+
+	It("works without variables", func() {
+		out := make([]float64, 3)
+		Expect(compile("1 + 2").EvalBatch(nil, out)).To(Succeed())
+		Expect(out).To(Equal([]float64{3, 3, 3}))
+	})
+
+	It("errors, when a column is missing", func() {
+		out := make([]float64, 3)
+		err := compile("a + c").EvalBatch(columns(3), out)
+		Expect(err).To(Equal(vm.ErrorVariableNotDefined))
+	})
+
+	It("errors, when a column has the wrong length", func() {
+		out := make([]float64, 4)
+		err := compile("a + b").EvalBatch(columns(3), out)
+		Expect(err).To(Equal(vm.ErrorColumnLength))
+	})
+
+	It("errors, when a row fails", func() {
+		out := make([]float64, 3)
+		err := compile("1 / a").EvalBatch(columns(3), out)
+		Expect(err).To(Equal(calculator.ErrorDivisionByZero))
+	})
+
+	Describe("parallel", func() {
+		It("returns the same results as the non parallel version", func() {
+			p := compile("a * b - a / b")
+			cols := columns(1000)
+			out1 := make([]float64, 1000)
+			out2 := make([]float64, 1000)
+
+			Expect(p.EvalBatch(cols, out1)).To(Succeed())
+			for _, shards := range []int{0, 1, 3, 8, 2000} {
+				Expect(p.EvalBatchParallel(cols, out2, shards)).To(Succeed())
+				Expect(out2).To(Equal(out1))
+			}
+		})
+
+		It("returns errors of any shard", func() {
+			cols := columns(100)
+			cols["b"][77] = 0
+			out := make([]float64, 100)
+			err := compile("a / b").EvalBatchParallel(cols, out, 4)
+			Expect(err).To(Equal(calculator.ErrorDivisionByZero))
+		})
+	})
+})
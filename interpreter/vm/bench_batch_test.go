@@ -0,0 +1,59 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/vm"
+	"github.com/relnod/calcgo/parser"
+)
+
+var (
+	batchStr  = "(a + 2) * 4 - (4 / 6) * b"
+	batchRows = 10000
+)
+
+func batchColumns() map[string][]float64 {
+	a := make([]float64, batchRows)
+	b := make([]float64, batchRows)
+	for i := range a {
+		a[i] = float64(i)
+		b[i] = float64(i) / 2
+	}
+
+	return map[string][]float64{"a": a, "b": b}
+}
+
+func BenchmarkBatchInterpreter(b *testing.B) {
+	cols := batchColumns()
+	out := make([]float64, batchRows)
+	for n := 0; n < b.N; n++ {
+		i := interpreter.NewInterpreter(batchStr)
+		i.EnableOptimizer()
+		for row := range out {
+			i.SetVar("a", cols["a"][row])
+			i.SetVar("b", cols["b"][row])
+			out[row], _ = i.GetResult()
+		}
+	}
+}
+
+func BenchmarkBatchVM(b *testing.B) {
+	cols := batchColumns()
+	out := make([]float64, batchRows)
+	for n := 0; n < b.N; n++ {
+		ast, _ := parser.Parse(batchStr)
+		p, _ := vm.CompileOptimized(&ast)
+		p.EvalBatch(cols, out)
+	}
+}
+
+func BenchmarkBatchVMParallel(b *testing.B) {
+	cols := batchColumns()
+	out := make([]float64, batchRows)
+	for n := 0; n < b.N; n++ {
+		ast, _ := parser.Parse(batchStr)
+		p, _ := vm.CompileOptimized(&ast)
+		p.EvalBatchParallel(cols, out, 4)
+	}
+}
@@ -0,0 +1,223 @@
+// Package vm contains a compiler, that translates an ast into bytecode and a
+// stack based virtual machine, that executes the bytecode.
+package vm
+
+import (
+	"errors"
+
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/parser"
+)
+
+// Errors, that can occur during compiling or running a program
+var (
+	ErrorMissingLeftChild        = errors.New("Error: Missing left child of node")
+	ErrorMissingRightChild       = errors.New("Error: Missing right child of node")
+	ErrorMissingFunctionArgument = errors.New("Error: Missing function argument")
+	ErrorInvalidNodeType         = errors.New("Error: Invalid node type")
+	ErrorVariableNotDefined      = errors.New("Error: A variable was not defined")
+)
+
+// Program holds the compiled bytecode of an ast.
+type Program struct {
+	Code   []Instruction
+	Consts []float64
+	Vars   []string
+
+	stackSize int
+}
+
+// compiler holds the state of the compiler.
+type compiler struct {
+	program *Program
+	vars    map[string]int32
+	depth   int
+}
+
+// Compile compiles an ast into a program.
+// Literals get converted at compile time.
+func Compile(ast parser.IAST) (*Program, error) {
+	if parser.IsEmpty(ast) {
+		return &Program{}, nil
+	}
+
+	c := &compiler{
+		program: &Program{},
+		vars:    make(map[string]int32),
+	}
+
+	if err := c.compileNode(ast.Root()); err != nil {
+		return nil, err
+	}
+
+	return c.program, nil
+}
+
+// CompileString parses a string and compiles the resulting ast into a
+// program.
+func CompileString(str string) (*Program, []error) {
+	if len(str) == 0 {
+		return &Program{}, nil
+	}
+
+	ast, errors := parser.Parse(str)
+	if errors != nil {
+		return nil, errors
+	}
+
+	p, err := Compile(&ast)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return p, nil
+}
+
+// CompileOptimized optimizes an ast and compiles the result into a program.
+// Constant sub-trees get folded by the optimizer and therefore only get
+// calculated once.
+func CompileOptimized(ast parser.IAST) (*Program, error) {
+	if parser.IsEmpty(ast) {
+		return &Program{}, nil
+	}
+
+	if !ast.Optimized() {
+		oast, err := optimizer.Optimize(ast)
+		if err != nil {
+			return nil, err
+		}
+		ast = oast
+	}
+
+	return Compile(ast)
+}
+
+// emit appends an instruction to the program and keeps track of the needed
+// stack size.
+func (c *compiler) emit(op Opcode, arg int32, stackChange int) {
+	c.program.Code = append(c.program.Code, Instruction{Op: op, Arg: arg})
+
+	c.depth += stackChange
+	if c.depth > c.program.stackSize {
+		c.program.stackSize = c.depth
+	}
+}
+
+// compileNode recursively compiles a node.
+func (c *compiler) compileNode(n parser.INode) error {
+	if parser.IsLiteral(n) {
+		return c.compileLiteral(n)
+	}
+
+	if parser.IsOperator(n) {
+		return c.compileOperator(n)
+	}
+
+	if parser.IsBinaryFunction(n) {
+		return c.compileBinaryFunction(n)
+	}
+
+	if parser.IsFunction(n) {
+		return c.compileFunction(n)
+	}
+
+	return ErrorInvalidNodeType
+}
+
+// compileLiteral compiles a number or variable node.
+func (c *compiler) compileLiteral(n parser.INode) error {
+	if n.GetType() == parser.NVar {
+		index, ok := c.vars[n.GetValue()]
+		if !ok {
+			index = int32(len(c.program.Vars))
+			c.vars[n.GetValue()] = index
+			c.program.Vars = append(c.program.Vars, n.GetValue())
+		}
+
+		c.emit(OpVar, index, 1)
+		return nil
+	}
+
+	value, err := n.Calculate(convertLiteral)
+	if err != nil {
+		return err
+	}
+
+	c.program.Consts = append(c.program.Consts, value)
+	c.emit(OpConst, int32(len(c.program.Consts)-1), 1)
+
+	return nil
+}
+
+// compileOperator compiles both child nodes and the operator itself.
+func (c *compiler) compileOperator(n parser.INode) error {
+	if n.Left() == nil {
+		return ErrorMissingLeftChild
+	}
+	if n.Right() == nil {
+		return ErrorMissingRightChild
+	}
+
+	if err := c.compileNode(n.Left()); err != nil {
+		return err
+	}
+	if err := c.compileNode(n.Right()); err != nil {
+		return err
+	}
+
+	switch n.GetType() {
+	case parser.NAdd:
+		c.emit(OpAdd, 0, -1)
+	case parser.NSub:
+		c.emit(OpSub, 0, -1)
+	case parser.NMult:
+		c.emit(OpMult, 0, -1)
+	case parser.NDiv:
+		c.emit(OpDiv, 0, -1)
+	default:
+		c.emit(OpOperator, int32(n.GetType()), -1)
+	}
+
+	return nil
+}
+
+// compileFunction compiles the function argument and the function itself.
+func (c *compiler) compileFunction(n parser.INode) error {
+	if n.Left() == nil {
+		return ErrorMissingFunctionArgument
+	}
+
+	if err := c.compileNode(n.Left()); err != nil {
+		return err
+	}
+
+	c.emit(OpFunction, int32(n.GetType()), 0)
+
+	return nil
+}
+
+// compileBinaryFunction compiles both function arguments and the function
+// itself.
+func (c *compiler) compileBinaryFunction(n parser.INode) error {
+	if n.Left() == nil || n.Right() == nil {
+		return ErrorMissingFunctionArgument
+	}
+
+	if err := c.compileNode(n.Left()); err != nil {
+		return err
+	}
+	if err := c.compileNode(n.Right()); err != nil {
+		return err
+	}
+
+	c.emit(OpBinaryFunction, int32(n.GetType()), -1)
+
+	return nil
+}
+
+// convertLiteral is the calculation visitor used to convert literals at
+// compile time. Already optimized nodes don't call the visitor.
+func convertLiteral(n parser.INode) (float64, error) {
+	return calculator.ConvertLiteral(n.GetValue(), n.GetType())
+}
@@ -0,0 +1,70 @@
+package vm
+
+import "strconv"
+
+// Opcode defines the operation of an instruction.
+type Opcode byte
+
+// Opcodes
+const (
+	// OpConst pushes the constant at index Arg onto the stack.
+	OpConst Opcode = iota
+	// OpVar pushes the variable at index Arg onto the stack.
+	OpVar
+
+	// OpAdd pops two values and pushes their sum.
+	OpAdd
+	// OpSub pops two values and pushes their difference.
+	OpSub
+	// OpMult pops two values and pushes their product.
+	OpMult
+	// OpDiv pops two values and pushes their quotient.
+	OpDiv
+	// OpOperator pops two values and pushes the result of the operator with
+	// the node type Arg.
+	OpOperator
+
+	// OpFunction pops one value and pushes the result of the function with the
+	// node type Arg.
+	OpFunction
+	// OpBinaryFunction pops two values and pushes the result of the function
+	// with the node type Arg.
+	OpBinaryFunction
+)
+
+var opcodes = [...]string{
+	OpConst:          "CONST",
+	OpVar:            "VAR",
+	OpAdd:            "ADD",
+	OpSub:            "SUB",
+	OpMult:           "MULT",
+	OpDiv:            "DIV",
+	OpOperator:       "OP",
+	OpFunction:       "FN",
+	OpBinaryFunction: "FN2",
+}
+
+// String converts an opcode to a string.
+func (o Opcode) String() string {
+	if int(o) < len(opcodes) {
+		return opcodes[o]
+	}
+
+	return "UNKNOWN"
+}
+
+// Instruction represents a single instruction of a program.
+type Instruction struct {
+	Op  Opcode
+	Arg int32
+}
+
+// String converts an instruction to a string.
+func (i Instruction) String() string {
+	switch i.Op {
+	case OpConst, OpVar, OpOperator, OpFunction, OpBinaryFunction:
+		return i.Op.String() + " " + strconv.Itoa(int(i.Arg))
+	}
+
+	return i.Op.String()
+}
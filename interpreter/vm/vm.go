@@ -0,0 +1,122 @@
+package vm
+
+import (
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/parser"
+)
+
+// VM holds the state of the virtual machine.
+type VM struct {
+	program *Program
+	stack   []float64
+	vars    []float64
+	defined []bool
+}
+
+// New returns a new virtual machine, that runs the given program.
+func New(p *Program) *VM {
+	return &VM{
+		program: p,
+		stack:   make([]float64, p.stackSize),
+		vars:    make([]float64, len(p.Vars)),
+		defined: make([]bool, len(p.Vars)),
+	}
+}
+
+// SetVar sets the value of a variable. Variables, that are not used by the
+// program get ignored.
+func (vm *VM) SetVar(name string, value float64) {
+	for i, v := range vm.program.Vars {
+		if v == name {
+			vm.vars[i] = value
+			vm.defined[i] = true
+			return
+		}
+	}
+}
+
+// Run executes the program and returns the result.
+// All variables have to be set up to this point.
+func (vm *VM) Run() (float64, error) {
+	for _, ok := range vm.defined {
+		if !ok {
+			return 0, ErrorVariableNotDefined
+		}
+	}
+
+	return vm.program.exec(vm.stack, vm.vars)
+}
+
+// exec executes the program with the given stack and variable values. The
+// stack needs to be big enough to hold stackSize values.
+func (p *Program) exec(stack []float64, vars []float64) (float64, error) {
+	if len(p.Code) == 0 {
+		return 0, nil
+	}
+
+	sp := 0
+
+	for _, in := range p.Code {
+		switch in.Op {
+		case OpConst:
+			stack[sp] = p.Consts[in.Arg]
+			sp++
+		case OpVar:
+			stack[sp] = vars[in.Arg]
+			sp++
+		case OpAdd:
+			sp--
+			stack[sp-1] += stack[sp]
+		case OpSub:
+			sp--
+			stack[sp-1] -= stack[sp]
+		case OpMult:
+			sp--
+			stack[sp-1] *= stack[sp]
+		case OpDiv:
+			sp--
+			if stack[sp] == 0 {
+				return 0, calculator.ErrorDivisionByZero
+			}
+			stack[sp-1] /= stack[sp]
+		case OpOperator:
+			sp--
+			result, err := calculator.CalculateOperator(stack[sp-1], stack[sp], parser.NodeType(in.Arg))
+			if err != nil {
+				return 0, err
+			}
+			stack[sp-1] = result
+		case OpFunction:
+			result, err := calculator.CalculateFunction(stack[sp-1], parser.NodeType(in.Arg))
+			if err != nil {
+				return 0, err
+			}
+			stack[sp-1] = result
+		case OpBinaryFunction:
+			sp--
+			result, err := calculator.CalculateBinaryFunction(stack[sp-1], stack[sp], parser.NodeType(in.Arg))
+			if err != nil {
+				return 0, err
+			}
+			stack[sp-1] = result
+		}
+	}
+
+	return stack[0], nil
+}
+
+// Run compiles and runs a given string.
+// Returns errors if lexing, parsing, compiling or running failed.
+func Run(str string) (float64, []error) {
+	p, errors := CompileString(str)
+	if errors != nil {
+		return 0, errors
+	}
+
+	result, err := New(p).Run()
+	if err != nil {
+		return 0, []error{err}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,190 @@
+package vm_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter"
+	"github.com/relnod/calcgo/interpreter/calculator"
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/interpreter/vm"
+	"github.com/relnod/calcgo/parser"
+)
+
+func TestVM(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "VM Suite")
+}
+
+var _ = Describe("VM", func() {
+	DescribeTable("behaves the same as the interpreter",
+		func(in string) {
+			expected, expErrs := interpreter.Interpret(in)
+			result, errs := vm.Run(in)
+			Ω(result).Should(BeNumerically("==", expected))
+			Expect(errs).To(Equal(expErrs))
+		},
+		Entry("empty", ""),
+		Entry("int", "1"),
+		Entry("dec", "-1.5"),
+		Entry("bin", "0b101"),
+		Entry("hex", "0x1A"),
+		Entry("exp", "2^3"),
+		Entry("addition", "1 + 2"),
+		Entry("subtraction", "1 - 2 - 3"),
+		Entry("multiplication", "2 * 3"),
+		Entry("division", "1 / 2 / 3"),
+		Entry("modulo", "13 % 6"),
+		Entry("or", "5 | 1"),
+		Entry("xor", "5 ^ 1"),
+		Entry("and", "5 & 1"),
+		Entry("shifts", "1 << 4 | 0xf0 >> 2 ^ -1 >>> 60"),
+		Entry("bitwise not", "~5 & ~~3"),
+		Entry("bitwise error", "~0.5 + 1"),
+		Entry("precedence", "1 + 2 * 3 - 4 / 2"),
+		Entry("brackets", "((2 + 3) / (1 + 2)) * 3"),
+		Entry("functions", "sqrt(4) + sin(1) * cos(1) - tan(1)"),
+		Entry("nested functions", "sqrt(sqrt(16) * 4)"),
+		Entry("integer functions", "5! + binom(5, 2) * gcd(12, 18) - mod(-7, 3) + isprime(7)"),
+		Entry("function error", "perm(3, 1) + (-1)!"),
+		Entry("division by zero", "1 / (1 - 1)"),
+		Entry("modulo by zero", "1 % (1 - 1)"),
+		Entry("negative modulo", "-7 % 3 + 7 % -3"),
+		Entry("parser error", "1 + $"),
+	)
+
+	DescribeTable("variables",
+		func(in string, vars map[string]float64, expected float64, expErr error) {
+			p, errs := vm.CompileString(in)
+			Expect(errs).To(BeNil())
+
+			machine := vm.New(p)
+			for name, value := range vars {
+				machine.SetVar(name, value)
+			}
+
+			result, err := machine.Run()
+			Ω(result).Should(BeNumerically("==", expected))
+			if expErr != nil {
+				Expect(err).To(Equal(expErr))
+			} else {
+				Expect(err).To(BeNil())
+			}
+		},
+		Entry("simple var", "a", map[string]float64{"a": 1.0}, 1.0, nil),
+		Entry("same var twice", "a * a", map[string]float64{"a": 3.0}, 9.0, nil),
+		Entry("multiple vars", "a + b", map[string]float64{"a": 1.0, "b": 2.0}, 3.0, nil),
+		Entry("var in function", "sqrt(a)", map[string]float64{"a": 4.0}, 2.0, nil),
+		Entry("unused vars get ignored", "1", map[string]float64{"a": 4.0}, 1.0, nil),
+		Entry("error when var is not set", "a + 1", map[string]float64{}, 0.0, vm.ErrorVariableNotDefined),
+		Entry("error when dividing by var, which is 0", "1 / a", map[string]float64{"a": 0.0}, 0.0,
+			calculator.ErrorDivisionByZero),
+	)
+
+	It("can be run multiple times", func() {
+		p, errs := vm.CompileString("(a + 2) * 4")
+		Expect(errs).To(BeNil())
+
+		machine := vm.New(p)
+		for i := 0.0; i < 3; i++ {
+			machine.SetVar("a", i)
+			result, err := machine.Run()
+			Expect(err).To(BeNil())
+			Ω(result).Should(BeNumerically("==", (i+2)*4))
+		}
+	})
+
+	It("compiles optimized asts", func() {
+		ast, errs := parser.Parse("sqrt(4) * a + 1")
+		Expect(errs).To(BeNil())
+		oast, err := optimizer.Optimize(&ast)
+		Expect(err).To(BeNil())
+
+		p, err := vm.Compile(oast)
+		Expect(err).To(BeNil())
+		Expect(p.Consts).To(Equal([]float64{2, 1}))
+
+		machine := vm.New(p)
+		machine.SetVar("a", 3)
+		result, err := machine.Run()
+		Expect(err).To(BeNil())
+		Ω(result).Should(BeNumerically("==", 7))
+	})
+
+	It("compiles empty asts", func() {
+		ast, errs := parser.Parse("")
+		Expect(errs).To(BeNil())
+
+		for _, compile := range []func(parser.IAST) (*vm.Program, error){vm.Compile, vm.CompileOptimized} {
+			for _, a := range []parser.IAST{&ast, &parser.AST{}, nil} {
+				p, err := compile(a)
+				Expect(err).To(BeNil())
+
+				result, err := vm.New(p).Run()
+				Expect(err).To(BeNil())
+				Ω(result).Should(BeNumerically("==", 0))
+			}
+		}
+	})
+})
+
+var _ = Describe("Compile()", func() {
+	It("emits the expected bytecode", func() {
+		p, errs := vm.CompileString("(1 + a) * sqrt(a) % 2")
+		Expect(errs).To(BeNil())
+
+		Expect(p.Code).To(Equal([]vm.Instruction{
+			{Op: vm.OpConst, Arg: 0},
+			{Op: vm.OpVar, Arg: 0},
+			{Op: vm.OpAdd},
+			{Op: vm.OpVar, Arg: 0},
+			{Op: vm.OpFunction, Arg: int32(parser.NFnSqrt)},
+			{Op: vm.OpMult},
+			{Op: vm.OpConst, Arg: 1},
+			{Op: vm.OpOperator, Arg: int32(parser.NMod)},
+		}))
+		Expect(p.Consts).To(Equal([]float64{1, 2}))
+		Expect(p.Vars).To(Equal([]string{"a"}))
+	})
+
+	DescribeTable("errors",
+		func(ast *parser.AST, expErr error) {
+			p, err := vm.Compile(ast)
+			Expect(p).To(BeNil())
+			Expect(err).To(Equal(expErr))
+		},
+		Entry("missing left child", &parser.AST{
+			Node: &parser.Node{
+				Type:       parser.NAdd,
+				RightChild: &parser.Node{Type: parser.NInt, Value: "1"},
+			},
+		}, vm.ErrorMissingLeftChild),
+		Entry("missing right child", &parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NAdd,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "1"},
+			},
+		}, vm.ErrorMissingRightChild),
+		Entry("missing function argument", &parser.AST{
+			Node: &parser.Node{Type: parser.NFnSqrt},
+		}, vm.ErrorMissingFunctionArgument),
+		Entry("invalid node type", &parser.AST{
+			Node: &parser.Node{Type: 3000},
+		}, vm.ErrorInvalidNodeType),
+		Entry("invalid integer", &parser.AST{
+			Node: &parser.Node{Type: parser.NInt, Value: "a"},
+		}, calculator.ErrorInvalidInteger),
+	)
+})
+
+var _ = Describe("Instruction", func() {
+	It("converts to string", func() {
+		Expect(vm.Instruction{Op: vm.OpConst, Arg: 1}.String()).To(Equal("CONST 1"))
+		Expect(vm.Instruction{Op: vm.OpAdd}.String()).To(Equal("ADD"))
+		Expect(vm.Opcode(math.MaxUint8).String()).To(Equal("UNKNOWN"))
+	})
+})
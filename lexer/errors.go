@@ -0,0 +1,36 @@
+package lexer
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Lexical errors
+var (
+	ErrorInvalidCharacter   = errors.New("Error: Invalid character")
+	ErrorInvalidDigit       = errors.New("Error: Invalid digit for base")
+	ErrorUnterminatedNumber = errors.New("Error: Unterminated number")
+	ErrorInvalidSeparator   = errors.New("Error: Invalid digit separator")
+)
+
+// Error reports a lexical error. Err is one of the lexical errors above. Value
+// is the invalid part of the input and Start and End are its byte offsets.
+// Line and Column are the position of Start, the column is counted in runes.
+type Error struct {
+	Err    error
+	Value  string
+	Start  int
+	End    int
+	Line   int
+	Column int
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return e.Err.Error() + ": '" + e.Value + "' at " + strconv.Itoa(e.Start) + "-" + strconv.Itoa(e.End)
+}
+
+// Unwrap returns the lexical error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -2,15 +2,52 @@ package lexer
 
 import (
 	"io"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/relnod/calcgo/token"
 )
 
 type stateFn func(*Lexer) token.Token
 
+// Position describes a position in the input. Offset is the byte offset,
+// starting at 0. Line and Column start at 1. The column is counted in runes.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// String returns the position in the form "line:column".
+func (p Position) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// startPosition is the position at the beginning of the input.
+var startPosition = Position{Offset: 0, Line: 1, Column: 1}
+
 // Lexer holds the state of the lexer.
 type Lexer struct {
-	buf BufferedReader
+	buf    BufferedReader
+	limit  *limitedBufferedReader
+	errors []error
+	failed bool
+
+	// last is the last token returned by Read.
+	last token.Token
+
+	// r is the last read rune and width its width in bytes. The width is 0,
+	// if the last read failed or the rune was backed up.
+	r     rune
+	width int
+
+	// pos is the position after the last read rune and prev the position
+	// before it. start and end are the positions of the current token.
+	pos   Position
+	prev  Position
+	start Position
+	end   Position
 }
 
 // Lex takes an io.Reader and returns a list of tokens.
@@ -53,35 +90,264 @@ func lexInternal(l *Lexer) []token.Token {
 
 // NewLexer returns a new lexer object.
 func NewLexer(r io.Reader) *Lexer {
-	return &Lexer{
-		buf: NewBufferedReader(r),
-	}
+	return newLexer(NewBufferedReader(r))
 }
 
 // NewLexerFromString returns a new lexer object.
 func NewLexerFromString(str string) *Lexer {
-	return &Lexer{
-		buf: NewBufferedReaderFromString(str),
-	}
+	return newLexer(NewBufferedReaderFromString(str))
 }
 
 // NewLexerFromBufferedReader returns a new lexer object.
 func NewLexerFromBufferedReader(r BufferedReader) *Lexer {
+	return newLexer(r)
+}
+
+func newLexer(r BufferedReader) *Lexer {
 	return &Lexer{
-		buf: r,
+		buf:   r,
+		pos:   startPosition,
+		prev:  startPosition,
+		start: startPosition,
+		end:   startPosition,
+	}
+}
+
+// SetMaxInputBytes limits the number of bytes read from the input. Once the
+// input exceeds the limit, a token with type InputTooLong gets returned,
+// followed by EOF. A limit of 0 disables the limit.
+func (l *Lexer) SetMaxInputBytes(max int) {
+	if l.limit != nil {
+		l.buf = l.limit.BufferedReader
+		l.limit = nil
+	}
+
+	// The io reader only needs to read one byte more than the limit, to
+	// detect that the input is too long.
+	if r, ok := l.buf.(*BufferedIOReader); ok {
+		if max > 0 {
+			r.limitRead(max + 1)
+		} else {
+			r.limitRead(0)
+		}
+	}
+
+	if max > 0 {
+		l.limit = &limitedBufferedReader{BufferedReader: l.buf, max: max}
+		l.buf = l.limit
 	}
 }
 
-// Read returns the next token.
+// Read returns the next token. If reading from the input fails, a token with
+// type ReadError gets returned, followed by EOF. Lexical errors and the read
+// error are returned by Err and Errors.
 func (l *Lexer) Read() token.Token {
-	token := lexAll(l)
+	if l.failed || (l.limit != nil && l.limit.exceeded) {
+		l.reset()
+		return l.createEmpty(token.EOF)
+	}
+
+	t := lexAll(l)
+	l.last = t
+
+	if l.limit != nil && l.limit.exceeded {
+		return l.createEmpty(token.InputTooLong)
+	}
+
+	if err := l.readErr(); err != nil {
+		l.failed = true
+		l.errors = append(l.errors, err)
+		return l.createEmpty(token.ReadError)
+	}
+
+	return t
+}
+
+// Err returns the first error, that occurred during lexing. It is either a
+// lexical error of type *Error or the error, that occurred while reading from
+// the input. Reaching the end of the input is not an error.
+func (l *Lexer) Err() error {
+	if len(l.errors) == 0 {
+		return nil
+	}
+
+	return l.errors[0]
+}
+
+// Position returns the start and end position of the last token returned by
+// Read.
+func (l *Lexer) Position() (start Position, end Position) {
+	return l.start, l.end
+}
+
+// Errors returns all errors, that occurred during lexing, in the order of the
+// input. A read error is always the last error, because lexing stops after it.
+func (l *Lexer) Errors() []error {
+	return l.errors
+}
+
+// pushError records a lexical error for the input from start to the current
+// position.
+func (l *Lexer) pushError(err error, start Position) {
+	l.pushErrorRange(err, start, l.pos)
+}
+
+// pushErrorRange records a lexical error for the input from start to end. Both
+// have to be inside of the current token.
+func (l *Lexer) pushErrorRange(err error, start Position, end Position) {
+	value := l.buf.All()[start.Offset-l.start.Offset : end.Offset-l.start.Offset]
+	l.errors = append(l.errors, &Error{
+		Err:    err,
+		Value:  string(value),
+		Start:  start.Offset,
+		End:    end.Offset,
+		Line:   start.Line,
+		Column: start.Column,
+	})
+}
+
+// next returns the next rune of the input. Invalid UTF-8 is returned as
+// utf8.RuneError with a width of one byte.
+func (l *Lexer) next() (rune, bool) {
+	b, ok := l.buf.Next()
+	if !ok {
+		l.width = 0
+		return 0, false
+	}
+
+	r, width := rune(b), 1
+	if b >= utf8.RuneSelf {
+		var p [utf8.UTFMax]byte
+		p[0] = b
+		n := 1
+		for ; n < utf8.UTFMax && !utf8.FullRune(p[:n]); n++ {
+			if p[n], ok = l.buf.Next(); !ok {
+				break
+			}
+		}
+
+		r, width = utf8.DecodeRune(p[:n])
+		for i := width; i < n; i++ {
+			l.buf.Backup()
+		}
+	}
+
+	l.r = r
+	l.width = width
+	l.prev = l.pos
+	l.pos.Offset += width
+	if r == '\n' {
+		l.pos.Line++
+		l.pos.Column = 1
+	} else {
+		l.pos.Column++
+	}
+
+	return r, true
+}
+
+// backup moves the position back before the last read rune. It can only be
+// called once after each call of next.
+func (l *Lexer) backup() {
+	if l.width == 0 {
+		return
+	}
+
+	for i := 0; i < l.width; i++ {
+		l.buf.Backup()
+	}
+	l.pos = l.prev
+	l.width = 0
+}
+
+// accept consumes the next character, if it is r. Returns true, if the
+// character was consumed.
+func (l *Lexer) accept(r rune) bool {
+	next, ok := l.next()
+	if ok && next == r {
+		return true
+	}
+	l.backup()
+
+	return false
+}
+
+// afterValue returns true, if the last token was a number, a variable, a
+// closing bracket or a postfix operator. A minus after a value is always an
+// operator and never the sign of a number.
+func (l *Lexer) afterValue() bool {
+	switch l.last.Type {
+	case token.ParenR, token.Percent, token.Factorial:
+		return true
+	}
+
+	return l.last.IsLiteral()
+}
+
+// isPercent returns true, if the current '%' is a percent sign and not the
+// modulo operator. A percent sign directly follows a value and isn't followed
+// by another value, so "15%" and "15% + 1" contain a percent sign, but "15%4",
+// "15% 4", "15%-4", "15%~a" and "15 % 4" are modulo operations.
+func (l *Lexer) isPercent() bool {
+	if !l.afterValue() || l.buf.StartPos() != l.last.End {
+		return false
+	}
+
+	return !l.startsValue()
+}
+
+// startsValue returns true, if the next rune, that isn't whitespace, starts a
+// value. A minus only starts a value, if it is the sign of a number, so "- 4"
+// doesn't start a value. Nothing gets consumed. The buffer keeps the current
+// token, so it can back up more than one rune.
+func (l *Lexer) startsValue() bool {
+	pos, prev := l.pos, l.prev
+
+	r, ok := l.next()
+	for ok && isWhiteSpace(r) {
+		r, ok = l.next()
+	}
+
+	starts := ok && (isDigit(r) || isLetter(r) || r == '.' || r == '(' || r == '~')
+	if ok && r == '-' {
+		r, ok = l.next()
+		starts = ok && (isDigit(r) || r == '.')
+	}
+
+	for i := pos.Offset; i < l.pos.Offset; i++ {
+		l.buf.Backup()
+	}
+	l.pos, l.prev, l.width = pos, prev, 0
 
-	return token
+	return starts
+}
+
+// reset starts a new token at the current position.
+func (l *Lexer) reset() {
+	l.buf.Reset()
+	l.start = l.pos
+}
+
+// readErr returns the read error of the underlying buffered reader, if it
+// reports read errors.
+func (l *Lexer) readErr() error {
+	buf := l.buf
+	if l.limit != nil {
+		buf = l.limit.BufferedReader
+	}
+
+	if r, ok := buf.(interface{ Err() error }); ok {
+		return r.Err()
+	}
+
+	return nil
 }
 
 // createToken takes a tokentype and a value to create a token, which it then
 // emits.
 func (l *Lexer) createToken(tokenType token.Type, value string) token.Token {
+	l.end = l.pos
+
 	return token.Token{
 		Type:  tokenType,
 		Value: value,
@@ -100,43 +366,76 @@ func (l *Lexer) createEmpty(tokenType token.Type) token.Token {
 	return l.createToken(tokenType, "")
 }
 
-// emitEmpty emits a new token with type tokenType and the current character.
+// invalidCharacterInNumber records an error for the current character and
+// emits a new token with type InvalidCharacterInNumber.
+func (l *Lexer) invalidCharacterInNumber() token.Token {
+	err := ErrorInvalidCharacter
+	if l.r < utf8.RuneSelf && (isDigit(l.r) || unicode.IsLetter(l.r)) {
+		err = ErrorInvalidDigit
+	}
+	l.pushError(err, l.prev)
+
+	return l.createSingle(token.InvalidCharacterInNumber)
+}
+
+// createNumber emits a new number token with type tokenType. An error gets
+// recorded, if the number has no digits after its prefix.
+func (l *Lexer) createNumber(tokenType token.Type, digits int) token.Token {
+	if digits == 0 {
+		l.pushError(ErrorUnterminatedNumber, l.start)
+	}
+
+	return l.create(tokenType)
+}
+
+// createSingle emits a new token with type tokenType and the current
+// character.
 func (l *Lexer) createSingle(tokenType token.Type) token.Token {
-	return l.createToken(tokenType, string(l.buf.Current()))
+	all := l.buf.All()
+	return l.createToken(tokenType, string(all[len(all)-l.width:]))
 }
 
 // lexAll is the entry state of the lexer state machine and also for all tokens.
 //
 // Transitions:
-//  - [0-9] -> lexNumber
-//  - [a-z] -> lexVariableOrFunction
-//  - rest  -> lexAll
+//  - [0-9]  -> lexNumber
+//  - \.     -> lexDecimal
+//  - [_\pL] -> lexVariableOrFunction
 func lexAll(l *Lexer) token.Token {
 	var tokenType token.Type
 
-	l.buf.Reset()
+	l.reset()
 
-	b, ok := l.buf.Next()
+	r, ok := l.next()
+	for ok && isWhiteSpace(r) {
+		l.reset()
+		r, ok = l.next()
+	}
 	if !ok {
-		// todo: return something else here. Maybe forward ok?
-		return token.Token{Type: token.EOF}
+		return l.createEmpty(token.EOF)
 	}
-	if isDigit(b) {
+	if isDigit(r) {
 		return lexNumber(l)
 	}
-	if isLetter(b) {
+	if isLetter(r) {
 		return lexVariableOrFunction(l)
 	}
-	if isWhiteSpace(b) {
-		return lexAll(l)
-	}
 
-	switch b {
+	switch r {
+	case '.':
+		return lexDecimal(l)
 	case '+':
 		tokenType = token.Plus
 	case '-':
-		if b, ok := l.buf.Next(); ok && isDigit(b) {
-			return lexNumber(l)
+		if !l.afterValue() {
+			r, ok := l.next()
+			if ok && isDigit(r) {
+				return lexNumber(l)
+			}
+			if ok && r == '.' {
+				return lexDecimal(l)
+			}
+			l.backup()
 		}
 		tokenType = token.Minus
 	case '*':
@@ -145,124 +444,157 @@ func lexAll(l *Lexer) token.Token {
 		tokenType = token.Div
 	case '%':
 		tokenType = token.Mod
+		if l.isPercent() {
+			tokenType = token.Percent
+		}
 	case '|':
 		tokenType = token.Or
 	case '^':
 		tokenType = token.Xor
 	case '&':
 		tokenType = token.And
+	case '<':
+		return lexShiftLeft(l)
+	case '>':
+		return lexShiftRight(l)
+	case '~':
+		tokenType = token.BitNot
 	case '(':
 		tokenType = token.ParenL
 	case ')':
 		tokenType = token.ParenR
+	case ',':
+		tokenType = token.Comma
+	case '!':
+		tokenType = token.Factorial
 	default:
+		l.pushError(ErrorInvalidCharacter, l.start)
 		return l.create(token.InvalidCharacter)
 	}
 
 	return l.createEmpty(tokenType)
 }
 
+// lexShiftLeft creates a left shift token. A single '<' is an invalid
+// character.
+func lexShiftLeft(l *Lexer) token.Token {
+	if !l.accept('<') {
+		l.pushError(ErrorInvalidCharacter, l.start)
+		return l.create(token.InvalidCharacter)
+	}
+
+	return l.createEmpty(token.ShiftLeft)
+}
+
+// lexShiftRight creates a right shift or an unsigned right shift token. A
+// single '>' is an invalid character.
+func lexShiftRight(l *Lexer) token.Token {
+	if !l.accept('>') {
+		l.pushError(ErrorInvalidCharacter, l.start)
+		return l.create(token.InvalidCharacter)
+	}
+	if l.accept('>') {
+		return l.createEmpty(token.UnsignedShiftRight)
+	}
+
+	return l.createEmpty(token.ShiftRight)
+}
+
 // lexNumber is the entry state for all number tokens.
 //
 // Transitions:
-//  - 0x       -> lexHex
-//  - 0b       -> lexBin
-//  - [0-9]+\. -> lexDecimal
-//  - [0-9]+\^ -> lexExponential
-//  - rest     -> lexAll
+//  - 0x          -> lexHex
+//  - 0b          -> lexBin
+//  - 0o          -> lexOct
+//  - [0-9_]+\.   -> lexDecimal
+//  - [0-9_]+[eE] -> lexScientific
+//  - [0-9_]+\^   -> lexExponential
+//  - rest        -> lexAll
 func lexNumber(l *Lexer) token.Token {
-	if l.buf.Current() == '0' {
-		b, ok := l.buf.Next()
+	if l.r == '0' {
+		r, ok := l.next()
 		if ok {
-			if b == 'x' {
+			switch r {
+			case 'x':
 				return lexHex(l)
-			}
-			if b == 'b' {
+			case 'b':
 				return lexBin(l)
+			case 'o':
+				return lexOct(l)
 			}
 		}
-		l.buf.Backup()
+		l.backup()
 	}
 
-	for {
-		b, ok := l.buf.Next()
-		if !ok {
-			break
-		}
-
-		if isDigit(b) {
-			continue
-		}
-
-		if b == '.' {
+	_, r, ok := l.scanDigits(isDigit, true)
+	if ok {
+		switch r {
+		case '.':
 			return lexDecimal(l)
-		}
-
-		if b == '^' {
+		case 'e', 'E':
+			return lexScientific(l)
+		case '^':
 			return lexExponential(l)
 		}
-
-		if isWhiteSpace(b) || b == ')' {
-			l.buf.Backup()
-			break
-		}
-
-		return l.createSingle(token.InvalidCharacterInNumber)
 	}
 
-	return l.create(token.Int)
+	return l.endNumber(token.Int, 1, r, ok)
 }
 
-// lexDecimal creates a decimal number token.
+// lexDecimal creates a decimal number token. The decimal point can't be
+// the last character.
 //
 // Transitions:
-//  -> lexAll
+//  - [eE] -> lexScientific
+//  - rest -> lexAll
 func lexDecimal(l *Lexer) token.Token {
-	for {
-		b, ok := l.buf.Next()
-		if !ok {
-			break
-		}
-
-		if isDigit(b) {
-			continue
-		}
-
-		if isWhiteSpace(b) || b == ')' {
-			l.buf.Backup()
-			break
-		}
-
-		return l.createSingle(token.InvalidCharacterInNumber)
+	digits, r, ok := l.scanDigits(isDigit, false)
+	if ok && digits > 0 && (r == 'e' || r == 'E') {
+		return lexScientific(l)
 	}
 
-	return l.create(token.Dec)
+	return l.endNumber(token.Dec, digits, r, ok)
 }
 
-// lexHex creates a hex number token.
+// lexScientific creates a number token in scientific notation.
 //
 // Transitions:
 //  -> lexAll
-func lexHex(l *Lexer) token.Token {
-	for {
-		b, ok := l.buf.Next()
-		if !ok {
-			break
-		}
+func lexScientific(l *Lexer) token.Token {
+	return lexExponent(l, token.Sci)
+}
 
-		if isHexDigit(b) {
-			continue
-		}
+// lexHex creates a hex number token.
+//
+// Transitions:
+//  - 0x[0-9a-fA-F_]*\. -> lexHexFloat
+//  - 0x[0-9a-fA-F_]+p  -> lexHexExponent
+//  - rest              -> lexAll
+func lexHex(l *Lexer) token.Token {
+	digits, r, ok := l.scanDigits(isHexDigit, true)
+	if ok && r == '.' {
+		return lexHexFloat(l, digits)
+	}
+	if ok && digits > 0 && (r == 'p' || r == 'P') {
+		return lexExponent(l, token.HexFloat)
+	}
 
-		if isWhiteSpace(b) || b == ')' {
-			l.buf.Backup()
-			break
-		}
+	return l.endNumber(token.Hex, digits, r, ok)
+}
 
-		return l.createSingle(token.InvalidCharacterInNumber)
+// lexHexFloat creates a hexadecimal floating-point number token. Like in Go,
+// it needs an exponent. intDigits is the number of digits before the point.
+//
+// Transitions:
+//  - [pP] -> lexHexExponent
+//  - rest -> lexAll
+func lexHexFloat(l *Lexer, intDigits int) token.Token {
+	digits, r, ok := l.scanDigits(isHexDigit, false)
+	if ok && intDigits+digits > 0 && (r == 'p' || r == 'P') {
+		return lexExponent(l, token.HexFloat)
 	}
 
-	return l.create(token.Hex)
+	return l.endNumber(token.HexFloat, 0, r, ok)
 }
 
 // lexBin creates a binary number token.
@@ -270,25 +602,17 @@ func lexHex(l *Lexer) token.Token {
 // Transitions:
 //  -> lexAll
 func lexBin(l *Lexer) token.Token {
-	for {
-		b, ok := l.buf.Next()
-		if !ok {
-			break
-		}
-
-		if isBinDigit(b) {
-			continue
-		}
-
-		if isWhiteSpace(b) || b == ')' {
-			l.buf.Backup()
-			break
-		}
-
-		return l.createSingle(token.InvalidCharacterInNumber)
-	}
+	digits, r, ok := l.scanDigits(isBinDigit, true)
+	return l.endNumber(token.Bin, digits, r, ok)
+}
 
-	return l.create(token.Bin)
+// lexOct creates an octal number token.
+//
+// Transitions:
+//  -> lexAll
+func lexOct(l *Lexer) token.Token {
+	digits, r, ok := l.scanDigits(isOctDigit, true)
+	return l.endNumber(token.Oct, digits, r, ok)
 }
 
 // lexExponential creates an exponential number token.
@@ -296,48 +620,96 @@ func lexBin(l *Lexer) token.Token {
 // Transitions:
 //  -> lexAll
 func lexExponential(l *Lexer) token.Token {
+	digits, r, ok := l.scanDigits(isDigit, false)
+	return l.endNumber(token.Exp, digits, r, ok)
+}
+
+// lexExponent reads the exponent of a number in scientific notation or of a
+// hexadecimal floating-point number, after the "e" or "p". The exponent is a
+// decimal number with an optional sign.
+//
+// Transitions:
+//  -> lexAll
+func lexExponent(l *Lexer, tokenType token.Type) token.Token {
+	if r, ok := l.next(); ok && r != '+' && r != '-' {
+		l.backup()
+	}
+
+	digits, r, ok := l.scanDigits(isDigit, false)
+	return l.endNumber(tokenType, digits, r, ok)
+}
+
+// scanDigits reads digits, that are valid for isValidDigit, and the
+// underscores separating them. An underscore has to follow a digit and to be
+// followed by a digit. Like in Go, it may also follow a base prefix.
+// afterDigit tells, if the previous character was a digit or a base prefix.
+// Returns the number of digits and the first other rune, which is
+// already read.
+func (l *Lexer) scanDigits(isValidDigit func(rune) bool, afterDigit bool) (int, rune, bool) {
+	digits := 0
+	separator := false
+	var separatorStart, separatorEnd Position
+
 	for {
-		b, ok := l.buf.Next()
-		if !ok {
-			break
+		r, ok := l.next()
+		if ok && isValidDigit(r) {
+			digits++
+			afterDigit = true
+			separator = false
+			continue
 		}
 
-		if isDigit(b) {
+		if ok && r == '_' {
+			if afterDigit {
+				separator, separatorStart, separatorEnd = true, l.prev, l.pos
+			} else {
+				l.pushError(ErrorInvalidSeparator, l.prev)
+				separator = false
+			}
+			afterDigit = false
 			continue
 		}
 
-		if isWhiteSpace(b) || b == ')' {
-			l.buf.Backup()
-			break
+		if separator {
+			l.pushErrorRange(ErrorInvalidSeparator, separatorStart, separatorEnd)
 		}
 
-		return l.createSingle(token.InvalidCharacterInNumber)
+		return digits, r, ok
 	}
+}
 
-	return l.create(token.Exp)
+// endNumber ends a number at the rune r, which was read after its last digit.
+// If r doesn't terminate the number, an invalid number token gets emitted.
+// Otherwise a number token with type tokenType gets emitted.
+func (l *Lexer) endNumber(tokenType token.Type, digits int, r rune, ok bool) token.Token {
+	if ok {
+		if !isDelimiter(r) {
+			return l.invalidCharacterInNumber()
+		}
+		l.backup()
+	}
+
+	return l.createNumber(tokenType, digits)
 }
 
-// lexVariableOrFunction creates a variable or function token.
+// lexVariableOrFunction creates a variable or function token. Like in Go, a
+// variable starts with a letter or underscore, followed by letters, digits and
+// underscores.
 //
 // Transitions:
 //  -> lexAll
 func lexVariableOrFunction(l *Lexer) token.Token {
 	for {
-		b, ok := l.buf.Next()
+		r, ok := l.next()
 		if !ok {
 			break
 		}
 
-		if isLetter(b) {
+		if isLetter(r) || unicode.IsDigit(r) {
 			continue
 		}
 
-		if isWhiteSpace(b) || b == ')' {
-			l.buf.Backup()
-			break
-		}
-
-		if b == '(' {
+		if r == '(' {
 			switch string(l.buf.All()) {
 			case "sqrt(":
 				return l.createEmpty(token.Sqrt)
@@ -347,38 +719,78 @@ func lexVariableOrFunction(l *Lexer) token.Token {
 				return l.createEmpty(token.Cos)
 			case "tan(":
 				return l.createEmpty(token.Tan)
+			case "gamma(":
+				return l.createEmpty(token.Gamma)
+			case "isprime(":
+				return l.createEmpty(token.IsPrime)
+			case "binom(":
+				return l.createEmpty(token.Binom)
+			case "perm(":
+				return l.createEmpty(token.Perm)
+			case "gcd(":
+				return l.createEmpty(token.Gcd)
+			case "lcm(":
+				return l.createEmpty(token.Lcm)
+			case "mod(":
+				return l.createEmpty(token.IntMod)
 			default:
 				return l.create(token.UnkownFunktion)
 			}
 		}
 
+		if isDelimiter(r) {
+			l.backup()
+			break
+		}
+
+		l.pushError(ErrorInvalidCharacter, l.prev)
 		return l.createSingle(token.InvalidCharacterInVariable)
 	}
 
 	return l.create(token.Var)
 }
 
-// isWhiteSpace checks if b is a whitespace character.
-func isWhiteSpace(b byte) bool {
-	return b == ' '
+// isWhiteSpace checks if r is a whitespace character.
+func isWhiteSpace(r rune) bool {
+	return unicode.IsSpace(r)
+}
+
+// isDelimiter checks if r terminates a number or variable. Delimiters are
+// whitespace, operators, brackets and commas.
+func isDelimiter(r rune) bool {
+	if isWhiteSpace(r) {
+		return true
+	}
+
+	switch r {
+	case '+', '-', '*', '/', '%', '!', '|', '^', '&', '<', '>', '~', '(', ')', ',':
+		return true
+	}
+
+	return false
+}
+
+// isDigit checks if r is a digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
 }
 
-// isDigit checks if b is a digit.
-func isDigit(b byte) bool {
-	return b >= '0' && b <= '9'
+// isHexDigit checks if r is a hexadecimal digit.
+func isHexDigit(r rune) bool {
+	return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
 }
 
-// isHexDigit checks if b is a hexadecimal digit.
-func isHexDigit(b byte) bool {
-	return isDigit(b) || b >= 'A' && b <= 'F'
+// isOctDigit checks if r is an octal digit.
+func isOctDigit(r rune) bool {
+	return r >= '0' && r <= '7'
 }
 
-// isBinDigit checks if b is 0 or 1.
-func isBinDigit(b byte) bool {
-	return b == '0' || b == '1'
+// isBinDigit checks if r is 0 or 1.
+func isBinDigit(r rune) bool {
+	return r == '0' || r == '1'
 }
 
-// isLetter checks if a is a letter.
-func isLetter(b byte) bool {
-	return b >= 'a' && b <= 'z'
+// isLetter checks if r is a letter or an underscore.
+func isLetter(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
 }
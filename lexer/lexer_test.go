@@ -1,7 +1,11 @@
 package lexer_test
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -124,6 +128,50 @@ var _ = Describe("LexString()", func() {
 				{Value: "a", Type: token.InvalidCharacterInNumber, Start: 0, End: 3},
 			}),
 		)
+
+		DescribeTable("octal", test,
+			Entry("1", "0o17", []token.Token{{Value: "0o17", Type: token.Oct, Start: 0, End: 4}}),
+			Entry("negative", "-0o7", []token.Token{{Value: "-0o7", Type: token.Oct, Start: 0, End: 4}}),
+			Entry("invalid digit", "0o8", []token.Token{
+				{Value: "8", Type: token.InvalidCharacterInNumber, Start: 0, End: 3},
+			}),
+		)
+
+		DescribeTable("scientific", test,
+			Entry("decimal", "1.5e3", []token.Token{{Value: "1.5e3", Type: token.Sci, Start: 0, End: 5}}),
+			Entry("integer", "1e3", []token.Token{{Value: "1e3", Type: token.Sci, Start: 0, End: 3}}),
+			Entry("negative exponent", "1.5e-3", []token.Token{{Value: "1.5e-3", Type: token.Sci, Start: 0, End: 6}}),
+			Entry("positive exponent", "6.02E+23", []token.Token{{Value: "6.02E+23", Type: token.Sci, Start: 0, End: 8}}),
+			Entry("negative", "-1e3", []token.Token{{Value: "-1e3", Type: token.Sci, Start: 0, End: 4}}),
+			Entry("followed by operator", "1e3-1", []token.Token{
+				{Value: "1e3", Type: token.Sci, Start: 0, End: 3},
+				{Value: "", Type: token.Minus, Start: 3, End: 4},
+				{Value: "1", Type: token.Int, Start: 4, End: 5},
+			}),
+		)
+
+		DescribeTable("hex floats", test,
+			Entry("1", "0x1.8p1", []token.Token{{Value: "0x1.8p1", Type: token.HexFloat, Start: 0, End: 7}}),
+			Entry("without point", "0x1p-2", []token.Token{{Value: "0x1p-2", Type: token.HexFloat, Start: 0, End: 6}}),
+			Entry("without integer part", "0x.8p0", []token.Token{{Value: "0x.8p0", Type: token.HexFloat, Start: 0, End: 6}}),
+		)
+
+		DescribeTable("other notations", test,
+			Entry("lowercase hex", "0xff", []token.Token{{Value: "0xff", Type: token.Hex, Start: 0, End: 4}}),
+			Entry("hex with e", "0x1e5", []token.Token{{Value: "0x1e5", Type: token.Hex, Start: 0, End: 5}}),
+			Entry("leading dot", ".5", []token.Token{{Value: ".5", Type: token.Dec, Start: 0, End: 2}}),
+			Entry("negative leading dot", "-.5", []token.Token{{Value: "-.5", Type: token.Dec, Start: 0, End: 3}}),
+			Entry("separators", "1_000_000", []token.Token{{Value: "1_000_000", Type: token.Int, Start: 0, End: 9}}),
+			Entry("separators in decimal", "1_000.000_1", []token.Token{
+				{Value: "1_000.000_1", Type: token.Dec, Start: 0, End: 11},
+			}),
+			Entry("separators in binary", "0b1010_1010", []token.Token{
+				{Value: "0b1010_1010", Type: token.Bin, Start: 0, End: 11},
+			}),
+			Entry("separator after hex prefix", "0x_ff", []token.Token{{Value: "0x_ff", Type: token.Hex, Start: 0, End: 5}}),
+			Entry("separator after octal prefix", "0o_17", []token.Token{{Value: "0o_17", Type: token.Oct, Start: 0, End: 5}}),
+			Entry("separator after binary prefix", "0b_1", []token.Token{{Value: "0b_1", Type: token.Bin, Start: 0, End: 4}}),
+		)
 	})
 
 	DescribeTable("operators", test,
@@ -135,6 +183,10 @@ var _ = Describe("LexString()", func() {
 		Entry("or", "|", []token.Token{{Value: "", Type: token.Or, Start: 0, End: 1}}),
 		Entry("xor", "^", []token.Token{{Value: "", Type: token.Xor, Start: 0, End: 1}}),
 		Entry("and", "&", []token.Token{{Value: "", Type: token.And, Start: 0, End: 1}}),
+		Entry("shift left", "<<", []token.Token{{Value: "", Type: token.ShiftLeft, Start: 0, End: 2}}),
+		Entry("shift right", ">>", []token.Token{{Value: "", Type: token.ShiftRight, Start: 0, End: 2}}),
+		Entry("unsigned shift right", ">>>", []token.Token{{Value: "", Type: token.UnsignedShiftRight, Start: 0, End: 3}}),
+		Entry("bitwise not", "~", []token.Token{{Value: "", Type: token.BitNot, Start: 0, End: 1}}),
 	)
 
 	DescribeTable("parens", test,
@@ -250,7 +302,7 @@ var _ = Describe("LexString()", func() {
 			{Value: "", Type: token.Plus, Start: 4, End: 5},
 			{Value: "b", Type: token.Var, Start: 7, End: 8},
 			{Value: "", Type: token.ParenR, Start: 8, End: 9},
-			{Value: "", Type: token.Minus, Start: 10, End: 12},
+			{Value: "", Type: token.Minus, Start: 10, End: 11},
 			{Value: "c", Type: token.Var, Start: 12, End: 13},
 		}),
 	)
@@ -260,6 +312,13 @@ var _ = Describe("LexString()", func() {
 		Entry("sin", "sin(", []token.Token{{Value: "", Type: token.Sin, Start: 0, End: 4}}),
 		Entry("cos", "cos(", []token.Token{{Value: "", Type: token.Cos, Start: 0, End: 4}}),
 		Entry("tan", "tan(", []token.Token{{Value: "", Type: token.Tan, Start: 0, End: 4}}),
+		Entry("gamma", "gamma(", []token.Token{{Value: "", Type: token.Gamma, Start: 0, End: 6}}),
+		Entry("isprime", "isprime(", []token.Token{{Value: "", Type: token.IsPrime, Start: 0, End: 8}}),
+		Entry("binom", "binom(", []token.Token{{Value: "", Type: token.Binom, Start: 0, End: 6}}),
+		Entry("perm", "perm(", []token.Token{{Value: "", Type: token.Perm, Start: 0, End: 5}}),
+		Entry("gcd", "gcd(", []token.Token{{Value: "", Type: token.Gcd, Start: 0, End: 4}}),
+		Entry("lcm", "lcm(", []token.Token{{Value: "", Type: token.Lcm, Start: 0, End: 4}}),
+		Entry("mod", "mod(", []token.Token{{Value: "", Type: token.IntMod, Start: 0, End: 4}}),
 
 		Entry("is var without paren", "sqrt", []token.Token{{Value: "sqrt", Type: token.Var, Start: 0, End: 4}}),
 
@@ -274,4 +333,529 @@ var _ = Describe("LexString()", func() {
 			{Value: "", Type: token.ParenR, Start: 8, End: 9},
 		}),
 	)
+
+	DescribeTable("operators without whitespace", test,
+		Entry("addition", "1+2", []token.Token{
+			{Value: "1", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Plus, Start: 1, End: 2},
+			{Value: "2", Type: token.Int, Start: 2, End: 3},
+		}),
+		Entry("brackets", "(3*4)", []token.Token{
+			{Value: "", Type: token.ParenL, Start: 0, End: 1},
+			{Value: "3", Type: token.Int, Start: 1, End: 2},
+			{Value: "", Type: token.Mult, Start: 2, End: 3},
+			{Value: "4", Type: token.Int, Start: 3, End: 4},
+			{Value: "", Type: token.ParenR, Start: 4, End: 5},
+		}),
+		Entry("subtraction", "1-2", []token.Token{
+			{Value: "1", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Minus, Start: 1, End: 2},
+			{Value: "2", Type: token.Int, Start: 2, End: 3},
+		}),
+		Entry("subtraction of a negative number", "1--2", []token.Token{
+			{Value: "1", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Minus, Start: 1, End: 2},
+			{Value: "-2", Type: token.Int, Start: 2, End: 4},
+		}),
+		Entry("subtraction after variable", "a-1", []token.Token{
+			{Value: "a", Type: token.Var, Start: 0, End: 1},
+			{Value: "", Type: token.Minus, Start: 1, End: 2},
+			{Value: "1", Type: token.Int, Start: 2, End: 3},
+		}),
+		Entry("subtraction after brackets", "(1)-2", []token.Token{
+			{Value: "", Type: token.ParenL, Start: 0, End: 1},
+			{Value: "1", Type: token.Int, Start: 1, End: 2},
+			{Value: "", Type: token.ParenR, Start: 2, End: 3},
+			{Value: "", Type: token.Minus, Start: 3, End: 4},
+			{Value: "2", Type: token.Int, Start: 4, End: 5},
+		}),
+		Entry("negative number in brackets", "(-2)", []token.Token{
+			{Value: "", Type: token.ParenL, Start: 0, End: 1},
+			{Value: "-2", Type: token.Int, Start: 1, End: 3},
+			{Value: "", Type: token.ParenR, Start: 3, End: 4},
+		}),
+		Entry("minus before variable", "-a", []token.Token{
+			{Value: "", Type: token.Minus, Start: 0, End: 1},
+			{Value: "a", Type: token.Var, Start: 1, End: 2},
+		}),
+		Entry("all operators", "a+b-c*d/e%f|g^h&i", []token.Token{
+			{Value: "a", Type: token.Var, Start: 0, End: 1},
+			{Value: "", Type: token.Plus, Start: 1, End: 2},
+			{Value: "b", Type: token.Var, Start: 2, End: 3},
+			{Value: "", Type: token.Minus, Start: 3, End: 4},
+			{Value: "c", Type: token.Var, Start: 4, End: 5},
+			{Value: "", Type: token.Mult, Start: 5, End: 6},
+			{Value: "d", Type: token.Var, Start: 6, End: 7},
+			{Value: "", Type: token.Div, Start: 7, End: 8},
+			{Value: "e", Type: token.Var, Start: 8, End: 9},
+			{Value: "", Type: token.Mod, Start: 9, End: 10},
+			{Value: "f", Type: token.Var, Start: 10, End: 11},
+			{Value: "", Type: token.Or, Start: 11, End: 12},
+			{Value: "g", Type: token.Var, Start: 12, End: 13},
+			{Value: "", Type: token.Xor, Start: 13, End: 14},
+			{Value: "h", Type: token.Var, Start: 14, End: 15},
+			{Value: "", Type: token.And, Start: 15, End: 16},
+			{Value: "i", Type: token.Var, Start: 16, End: 17},
+		}),
+		Entry("hex and binary", "0x1F*0b10", []token.Token{
+			{Value: "0x1F", Type: token.Hex, Start: 0, End: 4},
+			{Value: "", Type: token.Mult, Start: 4, End: 5},
+			{Value: "0b10", Type: token.Bin, Start: 5, End: 9},
+		}),
+		Entry("decimal", "1.5/2", []token.Token{
+			{Value: "1.5", Type: token.Dec, Start: 0, End: 3},
+			{Value: "", Type: token.Div, Start: 3, End: 4},
+			{Value: "2", Type: token.Int, Start: 4, End: 5},
+		}),
+		Entry("exponential", "2^3+1", []token.Token{
+			{Value: "2^3", Type: token.Exp, Start: 0, End: 3},
+			{Value: "", Type: token.Plus, Start: 3, End: 4},
+			{Value: "1", Type: token.Int, Start: 4, End: 5},
+		}),
+		Entry("function", "sqrt(2)*a", []token.Token{
+			{Value: "", Type: token.Sqrt, Start: 0, End: 5},
+			{Value: "2", Type: token.Int, Start: 5, End: 6},
+			{Value: "", Type: token.ParenR, Start: 6, End: 7},
+			{Value: "", Type: token.Mult, Start: 7, End: 8},
+			{Value: "a", Type: token.Var, Start: 8, End: 9},
+		}),
+	)
+
+	DescribeTable("Lexer works with function arguments and factorials", test,
+		Entry("arguments", "binom(n,2)", []token.Token{
+			{Value: "", Type: token.Binom, Start: 0, End: 6},
+			{Value: "n", Type: token.Var, Start: 6, End: 7},
+			{Value: "", Type: token.Comma, Start: 7, End: 8},
+			{Value: "2", Type: token.Int, Start: 8, End: 9},
+			{Value: "", Type: token.ParenR, Start: 9, End: 10},
+		}),
+		Entry("negative argument", "gcd(1, -2)", []token.Token{
+			{Value: "", Type: token.Gcd, Start: 0, End: 4},
+			{Value: "1", Type: token.Int, Start: 4, End: 5},
+			{Value: "", Type: token.Comma, Start: 5, End: 6},
+			{Value: "-2", Type: token.Int, Start: 7, End: 9},
+			{Value: "", Type: token.ParenR, Start: 9, End: 10},
+		}),
+		Entry("factorial", "n!-1", []token.Token{
+			{Value: "n", Type: token.Var, Start: 0, End: 1},
+			{Value: "", Type: token.Factorial, Start: 1, End: 2},
+			{Value: "", Type: token.Minus, Start: 2, End: 3},
+			{Value: "1", Type: token.Int, Start: 3, End: 4},
+		}),
+		Entry("factorial of number", "3.0!", []token.Token{
+			{Value: "3.0", Type: token.Dec, Start: 0, End: 3},
+			{Value: "", Type: token.Factorial, Start: 3, End: 4},
+		}),
+		Entry("percent after factorial", "3!%", []token.Token{
+			{Value: "3", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Factorial, Start: 1, End: 2},
+			{Value: "", Type: token.Percent, Start: 2, End: 3},
+		}),
+	)
+
+	DescribeTable("percent or modulo", test,
+		Entry("percent", "15%", []token.Token{
+			{Value: "15", Type: token.Int, Start: 0, End: 2},
+			{Value: "", Type: token.Percent, Start: 2, End: 3},
+		}),
+		Entry("percent before operator", "15% + a%- 1", []token.Token{
+			{Value: "15", Type: token.Int, Start: 0, End: 2},
+			{Value: "", Type: token.Percent, Start: 2, End: 3},
+			{Value: "", Type: token.Plus, Start: 4, End: 5},
+			{Value: "a", Type: token.Var, Start: 6, End: 7},
+			{Value: "", Type: token.Percent, Start: 7, End: 8},
+			{Value: "", Type: token.Minus, Start: 8, End: 9},
+			{Value: "1", Type: token.Int, Start: 10, End: 11},
+		}),
+		Entry("percent before minus and whitespace", "7% - 3", []token.Token{
+			{Value: "7", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Percent, Start: 1, End: 2},
+			{Value: "", Type: token.Minus, Start: 3, End: 4},
+			{Value: "3", Type: token.Int, Start: 5, End: 6},
+		}),
+		Entry("percent before minus and bracket", "7%-(3)", []token.Token{
+			{Value: "7", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Percent, Start: 1, End: 2},
+			{Value: "", Type: token.Minus, Start: 2, End: 3},
+			{Value: "", Type: token.ParenL, Start: 3, End: 4},
+			{Value: "3", Type: token.Int, Start: 4, End: 5},
+			{Value: "", Type: token.ParenR, Start: 5, End: 6},
+		}),
+		Entry("percent before minus at the end", "7%-", []token.Token{
+			{Value: "7", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Percent, Start: 1, End: 2},
+			{Value: "", Type: token.Minus, Start: 2, End: 3},
+		}),
+		Entry("percent after brackets", "(1)%)", []token.Token{
+			{Value: "", Type: token.ParenL, Start: 0, End: 1},
+			{Value: "1", Type: token.Int, Start: 1, End: 2},
+			{Value: "", Type: token.ParenR, Start: 2, End: 3},
+			{Value: "", Type: token.Percent, Start: 3, End: 4},
+			{Value: "", Type: token.ParenR, Start: 4, End: 5},
+		}),
+		Entry("modulo without whitespace", "15%4", []token.Token{
+			{Value: "15", Type: token.Int, Start: 0, End: 2},
+			{Value: "", Type: token.Mod, Start: 2, End: 3},
+			{Value: "4", Type: token.Int, Start: 3, End: 4},
+		}),
+		Entry("modulo with whitespace after the operator", "15% 4", []token.Token{
+			{Value: "15", Type: token.Int, Start: 0, End: 2},
+			{Value: "", Type: token.Mod, Start: 2, End: 3},
+			{Value: "4", Type: token.Int, Start: 4, End: 5},
+		}),
+		Entry("modulo with whitespace before a bracket", "a%\t (b)", []token.Token{
+			{Value: "a", Type: token.Var, Start: 0, End: 1},
+			{Value: "", Type: token.Mod, Start: 1, End: 2},
+			{Value: "", Type: token.ParenL, Start: 4, End: 5},
+			{Value: "b", Type: token.Var, Start: 5, End: 6},
+			{Value: "", Type: token.ParenR, Start: 6, End: 7},
+		}),
+		Entry("percent before whitespace", "15%  ", []token.Token{
+			{Value: "15", Type: token.Int, Start: 0, End: 2},
+			{Value: "", Type: token.Percent, Start: 2, End: 3},
+		}),
+		Entry("modulo of negative number", "7%-3", []token.Token{
+			{Value: "7", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Mod, Start: 1, End: 2},
+			{Value: "-3", Type: token.Int, Start: 2, End: 4},
+		}),
+		Entry("modulo of negative number after whitespace", "x% -.5", []token.Token{
+			{Value: "x", Type: token.Var, Start: 0, End: 1},
+			{Value: "", Type: token.Mod, Start: 1, End: 2},
+			{Value: "-.5", Type: token.Dec, Start: 3, End: 6},
+		}),
+		Entry("modulo of bitwise not", "7%~1", []token.Token{
+			{Value: "7", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Mod, Start: 1, End: 2},
+			{Value: "", Type: token.BitNot, Start: 2, End: 3},
+			{Value: "1", Type: token.Int, Start: 3, End: 4},
+		}),
+		Entry("modulo with whitespace", "15 % 4", []token.Token{
+			{Value: "15", Type: token.Int, Start: 0, End: 2},
+			{Value: "", Type: token.Mod, Start: 3, End: 4},
+			{Value: "4", Type: token.Int, Start: 5, End: 6},
+		}),
+		Entry("modulo before bracket", "a%(b)", []token.Token{
+			{Value: "a", Type: token.Var, Start: 0, End: 1},
+			{Value: "", Type: token.Mod, Start: 1, End: 2},
+			{Value: "", Type: token.ParenL, Start: 2, End: 3},
+			{Value: "b", Type: token.Var, Start: 3, End: 4},
+			{Value: "", Type: token.ParenR, Start: 4, End: 5},
+		}),
+		Entry("modulo after whitespace", "15 %", []token.Token{
+			{Value: "15", Type: token.Int, Start: 0, End: 2},
+			{Value: "", Type: token.Mod, Start: 3, End: 4},
+		}),
+	)
+
+	DescribeTable("shifts and bitwise not", test,
+		Entry("shifts without whitespace", "a<<2>>b>>>1", []token.Token{
+			{Value: "a", Type: token.Var, Start: 0, End: 1},
+			{Value: "", Type: token.ShiftLeft, Start: 1, End: 3},
+			{Value: "2", Type: token.Int, Start: 3, End: 4},
+			{Value: "", Type: token.ShiftRight, Start: 4, End: 6},
+			{Value: "b", Type: token.Var, Start: 6, End: 7},
+			{Value: "", Type: token.UnsignedShiftRight, Start: 7, End: 10},
+			{Value: "1", Type: token.Int, Start: 10, End: 11},
+		}),
+		Entry("negative shift count", "1 << -2", []token.Token{
+			{Value: "1", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.ShiftLeft, Start: 2, End: 4},
+			{Value: "-2", Type: token.Int, Start: 5, End: 7},
+		}),
+		Entry("bitwise not of negative number", "~-0xff", []token.Token{
+			{Value: "", Type: token.BitNot, Start: 0, End: 1},
+			{Value: "-0xff", Type: token.Hex, Start: 1, End: 6},
+		}),
+		Entry("bitwise not before bracket", "a&~(b)", []token.Token{
+			{Value: "a", Type: token.Var, Start: 0, End: 1},
+			{Value: "", Type: token.And, Start: 1, End: 2},
+			{Value: "", Type: token.BitNot, Start: 2, End: 3},
+			{Value: "", Type: token.ParenL, Start: 3, End: 4},
+			{Value: "b", Type: token.Var, Start: 4, End: 5},
+			{Value: "", Type: token.ParenR, Start: 5, End: 6},
+		}),
+		Entry("single less than", "1 < 2", []token.Token{
+			{Value: "1", Type: token.Int, Start: 0, End: 1},
+			{Value: "<", Type: token.InvalidCharacter, Start: 2, End: 3},
+			{Value: "2", Type: token.Int, Start: 4, End: 5},
+		}),
+		Entry("single greater than", "1>2", []token.Token{
+			{Value: "1", Type: token.Int, Start: 0, End: 1},
+			{Value: ">", Type: token.InvalidCharacter, Start: 1, End: 2},
+			{Value: "2", Type: token.Int, Start: 2, End: 3},
+		}),
+	)
+
+	DescribeTable("Lexer handles unicode whitespace", test,
+		Entry("tabs", "1\t+\t2", []token.Token{
+			{Value: "1", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Plus, Start: 2, End: 3},
+			{Value: "2", Type: token.Int, Start: 4, End: 5},
+		}),
+		Entry("newlines", "1\r\n+\n2\n", []token.Token{
+			{Value: "1", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Plus, Start: 3, End: 4},
+			{Value: "2", Type: token.Int, Start: 5, End: 6},
+		}),
+		Entry("no-break space", "1\u00a0+ 2", []token.Token{
+			{Value: "1", Type: token.Int, Start: 0, End: 1},
+			{Value: "", Type: token.Plus, Start: 3, End: 4},
+			{Value: "2", Type: token.Int, Start: 5, End: 6},
+		}),
+		Entry("after number in brackets", "(1\n)", []token.Token{
+			{Value: "", Type: token.ParenL, Start: 0, End: 1},
+			{Value: "1", Type: token.Int, Start: 1, End: 2},
+			{Value: "", Type: token.ParenR, Start: 3, End: 4},
+		}),
+	)
+
+	DescribeTable("identifiers", test,
+		Entry("uppercase", "Rate", []token.Token{{Value: "Rate", Type: token.Var, Start: 0, End: 4}}),
+		Entry("underscore", "_rate_2", []token.Token{{Value: "_rate_2", Type: token.Var, Start: 0, End: 7}}),
+		Entry("digits", "x1", []token.Token{{Value: "x1", Type: token.Var, Start: 0, End: 2}}),
+		Entry("greek letter", "α", []token.Token{{Value: "α", Type: token.Var, Start: 0, End: 2}}),
+		Entry("mixed scripts", "Δt + α", []token.Token{
+			{Value: "Δt", Type: token.Var, Start: 0, End: 3},
+			{Value: "", Type: token.Plus, Start: 4, End: 5},
+			{Value: "α", Type: token.Var, Start: 6, End: 8},
+		}),
+		Entry("unicode digits", "x٣", []token.Token{{Value: "x٣", Type: token.Var, Start: 0, End: 3}}),
+		Entry("function argument", "sqrt(α)", []token.Token{
+			{Value: "", Type: token.Sqrt, Start: 0, End: 5},
+			{Value: "α", Type: token.Var, Start: 5, End: 7},
+			{Value: "", Type: token.ParenR, Start: 7, End: 8},
+		}),
+		Entry("can't start with a digit", "1x", []token.Token{
+			{Value: "x", Type: token.InvalidCharacterInNumber, Start: 0, End: 2},
+		}),
+		Entry("invalid character", "a€", []token.Token{
+			{Value: "€", Type: token.InvalidCharacterInVariable, Start: 0, End: 4},
+		}),
+		Entry("invalid utf-8", "\xff", []token.Token{
+			{Value: "\xff", Type: token.InvalidCharacter, Start: 0, End: 1},
+		}),
+	)
+})
+
+var _ = DescribeTable("SetMaxInputBytes()",
+	func(in string, max int, expected []token.Token) {
+		l := lexer.NewLexerFromString(in)
+		l.SetMaxInputBytes(max)
+
+		tokens := make([]token.Token, 0)
+		for {
+			t := l.Read()
+			if t.Type == token.EOF {
+				break
+			}
+			tokens = append(tokens, t)
+		}
+
+		Expect(tokens).To(Equal(expected))
+	},
+	Entry("no limit", "1 + 2", 0, []token.Token{
+		{Value: "1", Type: token.Int, Start: 0, End: 1},
+		{Value: "", Type: token.Plus, Start: 2, End: 3},
+		{Value: "2", Type: token.Int, Start: 4, End: 5},
+	}),
+	Entry("within limit", "1 + 2", 5, []token.Token{
+		{Value: "1", Type: token.Int, Start: 0, End: 1},
+		{Value: "", Type: token.Plus, Start: 2, End: 3},
+		{Value: "2", Type: token.Int, Start: 4, End: 5},
+	}),
+	Entry("exceeds limit", "1 + 2", 4, []token.Token{
+		{Value: "1", Type: token.Int, Start: 0, End: 1},
+		{Value: "", Type: token.Plus, Start: 2, End: 3},
+		{Value: "", Type: token.InputTooLong, Start: 4, End: 4},
+	}),
+	Entry("exceeds limit in number", "12345", 3, []token.Token{
+		{Value: "", Type: token.InputTooLong, Start: 0, End: 3},
+	}),
+)
+
+var _ = Describe("Lexer", func() {
+	It("lexes large inputs", func() {
+		str := strings.Repeat("12 + ", 200000) + "12"
+		l := lexer.NewLexer(strings.NewReader(str))
+
+		n := 0
+		for {
+			t := l.Read()
+			if t.Type == token.EOF {
+				break
+			}
+			n++
+		}
+
+		Expect(n).To(Equal(400001))
+		Expect(l.Err()).To(BeNil())
+	})
+
+	It("returns read errors", func() {
+		err := errors.New("read failed")
+		l := lexer.NewLexer(io.MultiReader(strings.NewReader("1 + 2"), iotest.ErrReader(err)))
+
+		Expect(l.Read()).To(Equal(token.Token{Value: "1", Type: token.Int, Start: 0, End: 1}))
+		Expect(l.Read()).To(Equal(token.Token{Value: "", Type: token.Plus, Start: 2, End: 3}))
+		Expect(l.Read()).To(Equal(token.Token{Value: "", Type: token.ReadError, Start: 4, End: 5}))
+		Expect(l.Read().Type).To(Equal(token.EOF))
+		Expect(l.Err()).To(Equal(err))
+	})
+})
+
+var _ = DescribeTable("Errors()",
+	func(in string, expected []error) {
+		l := lexer.NewLexerFromString(in)
+		for l.Read().Type != token.EOF {
+		}
+
+		if expected != nil {
+			Expect(l.Errors()).To(Equal(expected))
+			Expect(l.Err()).To(Equal(expected[0]))
+		} else {
+			Expect(l.Errors()).To(BeNil())
+			Expect(l.Err()).To(BeNil())
+		}
+	},
+	Entry("no errors", "(1 + 0x1F) * a", nil),
+	Entry("invalid character", "1 + $", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidCharacter, Value: "$", Start: 4, End: 5, Line: 1, Column: 5},
+	}),
+	Entry("invalid character in number", "12$ + 1", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidCharacter, Value: "$", Start: 2, End: 3, Line: 1, Column: 3},
+	}),
+	Entry("invalid character after a line break", "15%\n 4 $", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidCharacter, Value: "$", Start: 7, End: 8, Line: 2, Column: 4},
+	}),
+	Entry("single greater than", "1 > 2", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidCharacter, Value: ">", Start: 2, End: 3, Line: 1, Column: 3},
+	}),
+	Entry("invalid character in variable", "ab$ + 1", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidCharacter, Value: "$", Start: 2, End: 3, Line: 1, Column: 3},
+	}),
+	Entry("invalid digit in integer", "12a", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidDigit, Value: "a", Start: 2, End: 3, Line: 1, Column: 3},
+	}),
+	Entry("invalid digit in binary", "0b102", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidDigit, Value: "2", Start: 4, End: 5, Line: 1, Column: 5},
+	}),
+	Entry("invalid digit in hex", "0x1G", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidDigit, Value: "G", Start: 3, End: 4, Line: 1, Column: 4},
+	}),
+	Entry("unterminated decimal", "1. + 2", []error{
+		&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "1.", Start: 0, End: 2, Line: 1, Column: 1},
+	}),
+	Entry("unterminated hex", "(0x)", []error{
+		&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "0x", Start: 1, End: 3, Line: 1, Column: 2},
+	}),
+	Entry("unterminated binary", "0b", []error{
+		&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "0b", Start: 0, End: 2, Line: 1, Column: 1},
+	}),
+	Entry("unterminated exponential", "2^ + 1", []error{
+		&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "2^", Start: 0, End: 2, Line: 1, Column: 1},
+	}),
+	Entry("unterminated scientific", "1e+", []error{
+		&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "1e+", Start: 0, End: 3, Line: 1, Column: 1},
+	}),
+	Entry("hex float without exponent", "0x1.8", []error{
+		&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "0x1.8", Start: 0, End: 5, Line: 1, Column: 1},
+	}),
+	Entry("separator after prefix", "0x_1 + 0o_7 + 0b_1", nil),
+	Entry("leading separator", "1._5", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidSeparator, Value: "_", Start: 2, End: 3, Line: 1, Column: 3},
+	}),
+	Entry("double separator after prefix", "0b__1", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidSeparator, Value: "_", Start: 3, End: 4, Line: 1, Column: 4},
+	}),
+	Entry("separator after prefix without digits", "0o_", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidSeparator, Value: "_", Start: 2, End: 3, Line: 1, Column: 3},
+		&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "0o_", Start: 0, End: 3, Line: 1, Column: 1},
+	}),
+	Entry("trailing separator", "1_ + 2", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidSeparator, Value: "_", Start: 1, End: 2, Line: 1, Column: 2},
+	}),
+	Entry("double separator", "1__0", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidSeparator, Value: "_", Start: 2, End: 3, Line: 1, Column: 3},
+	}),
+	Entry("separator before point", "1_.5", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidSeparator, Value: "_", Start: 1, End: 2, Line: 1, Column: 2},
+	}),
+	Entry("multiple errors", "$ + 0b2", []error{
+		&lexer.Error{Err: lexer.ErrorInvalidCharacter, Value: "$", Start: 0, End: 1, Line: 1, Column: 1},
+		&lexer.Error{Err: lexer.ErrorInvalidDigit, Value: "2", Start: 6, End: 7, Line: 1, Column: 7},
+	}),
+)
+
+var _ = Describe("Error", func() {
+	It("has a message with the position", func() {
+		err := &lexer.Error{Err: lexer.ErrorInvalidCharacter, Value: "$", Start: 4, End: 5}
+		Expect(err.Error()).To(Equal("Error: Invalid character: '$' at 4-5"))
+	})
+
+	It("unwraps to the lexical error", func() {
+		var err error = &lexer.Error{Err: lexer.ErrorInvalidDigit, Value: "2", Start: 4, End: 5}
+		Expect(errors.Is(err, lexer.ErrorInvalidDigit)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Position()", func() {
+	It("returns line and column of the last token", func() {
+		l := lexer.NewLexerFromString("α +\n\tΔt")
+
+		type position struct {
+			start lexer.Position
+			end   lexer.Position
+		}
+		var positions []position
+		for l.Read().Type != token.EOF {
+			start, end := l.Position()
+			positions = append(positions, position{start, end})
+		}
+
+		Expect(positions).To(Equal([]position{
+			{lexer.Position{Offset: 0, Line: 1, Column: 1}, lexer.Position{Offset: 2, Line: 1, Column: 2}},
+			{lexer.Position{Offset: 3, Line: 1, Column: 3}, lexer.Position{Offset: 4, Line: 1, Column: 4}},
+			{lexer.Position{Offset: 6, Line: 2, Column: 2}, lexer.Position{Offset: 9, Line: 2, Column: 4}},
+		}))
+	})
+
+	It("positions the EOF token at the end of the input", func() {
+		l := lexer.NewLexerFromString("α +\n\tΔt  ")
+		for l.Read().Type != token.EOF {
+		}
+
+		Expect(l.Read()).To(Equal(token.Token{Type: token.EOF, Start: 11, End: 11}))
+		start, end := l.Position()
+		Expect(start).To(Equal(lexer.Position{Offset: 11, Line: 2, Column: 6}))
+		Expect(end).To(Equal(start))
+	})
+
+	It("formats as line:column", func() {
+		Expect(lexer.Position{Offset: 12, Line: 2, Column: 5}.String()).To(Equal("2:5"))
+	})
+
+	It("reports the position of lexical errors", func() {
+		l := lexer.NewLexerFromString("α +\nΔt €")
+		for l.Read().Type != token.EOF {
+		}
+
+		Expect(l.Errors()).To(Equal([]error{
+			&lexer.Error{Err: lexer.ErrorInvalidCharacter, Value: "€", Start: 9, End: 12, Line: 2, Column: 4},
+		}))
+	})
+
+	It("decodes runes across chunk boundaries", func() {
+		str := strings.Repeat("α + ", 3000) + "α"
+		tokens := lexer.Lex(strings.NewReader(str))
+
+		Expect(tokens).To(HaveLen(6001))
+		for i, t := range tokens {
+			if i%2 == 0 {
+				Expect(t.Value).To(Equal("α"))
+			} else {
+				Expect(t.Type).To(Equal(token.Plus))
+			}
+		}
+	})
 })
@@ -9,6 +9,7 @@ import (
 // BufferedLexer wraps the lexer around a buffer,
 type BufferedLexer struct {
 	buf chan token.Token
+	eof token.Token
 	l   *Lexer
 }
 
@@ -20,7 +21,7 @@ func NewBufferedlLexer(r io.Reader) *BufferedLexer {
 		l:   NewLexer(r),
 	}
 
-	b.run()
+	go b.run()
 
 	return b
 }
@@ -40,7 +41,24 @@ func NewBufferedLexerFromString(str string) *BufferedLexer {
 
 // Read returns the next token from the token chanel.
 func (b *BufferedLexer) Read() token.Token {
-	return <-b.buf
+	t, ok := <-b.buf
+	if !ok {
+		return b.eof
+	}
+
+	return t
+}
+
+// Err returns the first error, that occurred during lexing. It must only be
+// called after a token of type ReadError or EOF was read.
+func (b *BufferedLexer) Err() error {
+	return b.l.Err()
+}
+
+// Errors returns all errors, that occurred during lexing. It must only be
+// called after a token of type ReadError or EOF was read.
+func (b *BufferedLexer) Errors() []error {
+	return b.l.Errors()
 }
 
 // Start starts the parallel lexer
@@ -48,6 +66,7 @@ func (b *BufferedLexer) run() {
 	for {
 		t := b.l.Read()
 		if t.Type == token.EOF {
+			b.eof = t
 			break
 		}
 		b.buf <- t
@@ -9,8 +9,8 @@ import (
 
 var _ = Describe("BufferedLexer", func() {
 	It("behaves the same as normal lexer", func() {
-		l1 := lexer.NewLexerFromString("1 + 2")
-		l2 := lexer.NewBufferedLexerFromString("1 + 2")
+		l1 := lexer.NewLexerFromString("1 + 2 ")
+		l2 := lexer.NewBufferedLexerFromString("1 + 2 ")
 
 		for {
 			t1 := l1.Read()
@@ -21,5 +21,7 @@ var _ = Describe("BufferedLexer", func() {
 				break
 			}
 		}
+
+		Expect(l2.Read()).To(Equal(token.Token{Type: token.EOF, Start: 6, End: 6}))
 	})
 })
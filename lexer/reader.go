@@ -31,12 +31,25 @@ type BufferedReader interface {
 	Reset()
 }
 
+// chunkSize is the number of bytes, that get read from an io.Reader at once.
+const chunkSize = 4096
+
+// maxEmptyReads is the number of consecutive reads without data and without
+// error, after which reading gets aborted with io.ErrNoProgress.
+const maxEmptyReads = 100
+
 // BufferedIOReader implements the BufferedReader with an io.Reader as input.
+// The input gets read in chunks. Only the current token and the unread part of
+// the last chunk are kept in memory.
 type BufferedIOReader struct {
 	reader io.Reader
 	buf    []byte
+	off    int
 	pos    int
 	start  int
+	read   int
+	max    int
+	err    error
 }
 
 // NewBufferedReader returns a new buffered reader, that reads from the given
@@ -44,7 +57,8 @@ type BufferedIOReader struct {
 func NewBufferedReader(reader io.Reader) *BufferedIOReader {
 	return &BufferedIOReader{
 		reader: reader,
-		buf:    make([]byte, 0),
+		buf:    make([]byte, 0, chunkSize),
+		off:    0,
 		pos:    0,
 		start:  0,
 	}
@@ -57,36 +71,31 @@ func (r *BufferedIOReader) StartPos() int {
 
 // CurrPos returns the current position relative to the total input.
 func (r *BufferedIOReader) CurrPos() int {
-	return r.pos + r.start
+	return r.start + r.pos - r.off
 }
 
 // Current returns the byte at the current position.
 func (r *BufferedIOReader) Current() byte {
+	if r.pos == 0 {
+		return 0
+	}
 	return r.buf[r.pos-1]
 }
 
 // All returns the whole content of the buffer.
 func (r *BufferedIOReader) All() []byte {
-	return r.buf[0:r.pos]
+	return r.buf[r.off:r.pos]
 }
 
 // Next returns the next byte read from the input. If an error occurred
 // during the read process or if the reader is at the end of its input the
-// second return value is false.
+// second return value is false. Errors other than io.EOF are returned by
+// Err().
 func (r *BufferedIOReader) Next() (byte, bool) {
-	if r.pos < len(r.buf) {
-		r.pos++
-		return r.Current(), true
-	}
-
-	bt := make([]byte, 1, 1)
-	_, err := r.reader.Read(bt)
-	if err != nil {
+	if r.pos >= len(r.buf) && !r.fill() {
 		return 0, false
 	}
 
-	r.buf = append(r.buf, bt[0])
-
 	r.pos++
 	return r.Current(), true
 }
@@ -98,9 +107,75 @@ func (r *BufferedIOReader) Backup() {
 
 // Reset resets the buffer content.
 func (r *BufferedIOReader) Reset() {
-	r.buf = r.buf[r.pos:len(r.buf)]
 	r.start = r.CurrPos()
-	r.pos = 0
+	r.off = r.pos
+}
+
+// Err returns the first error, that occurred while reading from the
+// io.Reader. Reaching the end of the input is not an error.
+func (r *BufferedIOReader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+
+	return r.err
+}
+
+// limitRead stops reading from the io.Reader after max bytes in total. A
+// limit of 0 disables the limit.
+func (r *BufferedIOReader) limitRead(max int) {
+	r.max = max
+}
+
+// fill reads the next chunk from the io.Reader. The content before the
+// current token gets discarded first, so the buffer only grows, if a single
+// token doesn't fit into it. Returns false, if no bytes could be read.
+func (r *BufferedIOReader) fill() bool {
+	if r.err != nil {
+		return false
+	}
+
+	if r.off > 0 {
+		n := copy(r.buf, r.buf[r.off:])
+		r.buf = r.buf[:n]
+		r.pos -= r.off
+		r.off = 0
+	}
+
+	if len(r.buf) == cap(r.buf) {
+		buf := make([]byte, len(r.buf), 2*cap(r.buf)+chunkSize)
+		copy(buf, r.buf)
+		r.buf = buf
+	}
+
+	end := cap(r.buf)
+	if r.max > 0 {
+		if r.read >= r.max {
+			r.err = io.EOF
+			return false
+		}
+		if end-len(r.buf) > r.max-r.read {
+			end = len(r.buf) + r.max - r.read
+		}
+	}
+
+	for i := 0; i < maxEmptyReads; i++ {
+		n, err := r.reader.Read(r.buf[len(r.buf):end])
+		r.buf = r.buf[:len(r.buf)+n]
+		r.read += n
+		if err != nil {
+			r.err = err
+		}
+		if n > 0 {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+
+	r.err = io.ErrNoProgress
+	return false
 }
 
 // StaticBufferedReader implements a buffered reader with a string as input.
@@ -164,3 +239,26 @@ func (r *StaticBufferedReader) Backup() {
 func (r *StaticBufferedReader) Reset() {
 	r.start = r.pos
 }
+
+// limitedBufferedReader wraps a buffered reader and stops reading after a
+// maximum number of bytes.
+type limitedBufferedReader struct {
+	BufferedReader
+	max      int
+	exceeded bool
+}
+
+// Next returns the next byte read from the input. Returns false if the end of
+// the input or the maximum number of bytes is reached.
+func (r *limitedBufferedReader) Next() (byte, bool) {
+	if r.CurrPos() < r.max {
+		return r.BufferedReader.Next()
+	}
+
+	if _, ok := r.BufferedReader.Next(); ok {
+		r.BufferedReader.Backup()
+		r.exceeded = true
+	}
+
+	return 0, false
+}
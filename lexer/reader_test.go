@@ -2,6 +2,10 @@ package lexer_test
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing/iotest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,11 +25,87 @@ var _ = Describe("BufferedIOReader", func() {
 		r := bytes.NewReader([]byte(str))
 		return lexer.NewBufferedReader(r)
 	})
+
+	It("reads input larger than a single chunk", func() {
+		str := strings.Repeat("0123456789", 1000)
+		r := lexer.NewBufferedReader(strings.NewReader(str))
+
+		for i := 0; i < len(str); i++ {
+			if i%7 == 0 {
+				r.Reset()
+			}
+			b, ok := r.Next()
+			Expect(ok).To(BeTrue())
+			Expect(b).To(Equal(str[i]))
+			Expect(r.CurrPos()).To(Equal(i + 1))
+		}
+		_, ok := r.Next()
+		Expect(ok).To(BeFalse())
+		Expect(r.Err()).To(BeNil())
+	})
+
+	It("keeps the buffer content across chunks", func() {
+		str := strings.Repeat("a", 10000)
+		r := lexer.NewBufferedReader(iotest.OneByteReader(strings.NewReader(str)))
+
+		r.Next()
+		r.Reset()
+		for {
+			if _, ok := r.Next(); !ok {
+				break
+			}
+		}
+
+		Expect(r.StartPos()).To(Equal(1))
+		Expect(r.CurrPos()).To(Equal(len(str)))
+		Expect(string(r.All())).To(Equal(str[1:]))
+	})
+
+	It("returns the data read together with io.EOF", func() {
+		r := lexer.NewBufferedReader(iotest.DataErrReader(strings.NewReader("ab")))
+
+		b, ok := r.Next()
+		Expect(b).To(Equal(uint8('a')))
+		Expect(ok).To(BeTrue())
+		b, ok = r.Next()
+		Expect(b).To(Equal(uint8('b')))
+		Expect(ok).To(BeTrue())
+		_, ok = r.Next()
+		Expect(ok).To(BeFalse())
+		Expect(r.Err()).To(BeNil())
+	})
+
+	It("returns read errors", func() {
+		err := errors.New("read failed")
+		r := lexer.NewBufferedReader(io.MultiReader(strings.NewReader("ab"), iotest.ErrReader(err)))
+
+		r.Next()
+		r.Next()
+		_, ok := r.Next()
+		Expect(ok).To(BeFalse())
+		Expect(r.Err()).To(Equal(err))
+		Expect(r.CurrPos()).To(Equal(2))
+	})
+
+	It("stops reading from a reader without progress", func() {
+		r := lexer.NewBufferedReader(emptyReader{})
+
+		_, ok := r.Next()
+		Expect(ok).To(BeFalse())
+		Expect(r.Err()).To(Equal(io.ErrNoProgress))
+	})
 })
 
+// emptyReader is an io.Reader, that never returns data or an error.
+type emptyReader struct{}
+
+func (emptyReader) Read(p []byte) (int, error) {
+	return 0, nil
+}
+
 func describeBufferedReader(newReader generator) {
 	It("has correct initial state", func() {
-		r := lexer.NewBufferedReaderFromString("test")
+		r := newReader("test")
 		Expect(r.StartPos()).To(BeZero())
 		Expect(r.CurrPos()).To(BeZero())
 		Expect(r.Current()).To(BeZero())
@@ -33,7 +113,7 @@ func describeBufferedReader(newReader generator) {
 	})
 
 	It("returns correct next byte and has correct state afterwards", func() {
-		r := lexer.NewBufferedReaderFromString("test")
+		r := newReader("test")
 		b, ok := r.Next()
 		Expect(b).To(Equal(uint8('t')))
 		Expect(ok).To(BeTrue())
@@ -45,7 +125,7 @@ func describeBufferedReader(newReader generator) {
 	})
 
 	It("returns false at end of input", func() {
-		r := lexer.NewBufferedReaderFromString("t")
+		r := newReader("t")
 		r.Next()
 		b, ok := r.Next()
 		Expect(b).To(BeZero())
@@ -53,7 +133,7 @@ func describeBufferedReader(newReader generator) {
 	})
 
 	It("backups correctly", func() {
-		r := lexer.NewBufferedReaderFromString("test")
+		r := newReader("test")
 		r.Next()
 		r.Backup()
 
@@ -61,7 +141,7 @@ func describeBufferedReader(newReader generator) {
 	})
 
 	It("has correct state after resetting", func() {
-		r := lexer.NewBufferedReaderFromString("test")
+		r := newReader("test")
 		r.Next()
 		r.Reset()
 
@@ -76,7 +156,7 @@ func describeBufferedReader(newReader generator) {
 	})
 
 	It("has correct state after backup and resetting", func() {
-		r := lexer.NewBufferedReaderFromString("test")
+		r := newReader("test")
 		r.Next()
 		r.Backup()
 		r.Reset()
@@ -21,6 +21,9 @@ const (
 	NBin
 	NHex
 	NExp
+	NOct
+	NSci
+	NHexFloat
 
 	// Variable
 	NVar
@@ -36,6 +39,9 @@ const (
 	NOr
 	NXor
 	NAnd
+	NShiftLeft
+	NShiftRight
+	NUnsignedShiftRight
 	operatorEnd
 
 	functionBeg
@@ -44,9 +50,94 @@ const (
 	NFnSin
 	NFnCos
 	NFnTan
+	NFnGamma
+	NFnIsPrime
+	NPercent
+	NFactorial
+	NBitNot
+
+	binaryFunctionBeg
+	// Functions with two arguments
+	NFnBinom
+	NFnPerm
+	NFnGcd
+	NFnLcm
+	NFnMod
 	functionEnd
 )
 
+var nodeTypes = [...]string{
+	NError:           "Error",
+	NInvalidNumber:   "InvalidNumber",
+	NInvalidVariable: "InvalidVariable",
+	NInvalidOperator: "InvalidOperator",
+	NInvalidFunction: "InvalidFunction",
+
+	NInt: "Int",
+	NDec: "Dec",
+	NBin: "Bin",
+	NHex: "Hex",
+	NExp: "Exp",
+
+	NOct:      "Oct",
+	NSci:      "Sci",
+	NHexFloat: "HexFloat",
+
+	NVar: "Var",
+
+	NAdd:  "Add",
+	NSub:  "Sub",
+	NMult: "Mult",
+	NDiv:  "Div",
+	NMod:  "Mod",
+	NOr:   "Or",
+	NXor:  "Xor",
+	NAnd:  "And",
+
+	NShiftLeft:          "ShiftLeft",
+	NShiftRight:         "ShiftRight",
+	NUnsignedShiftRight: "UnsignedShiftRight",
+
+	NFnSqrt: "Sqrt",
+	NFnSin:  "Sin",
+	NFnCos:  "Cos",
+	NFnTan:  "Tan",
+
+	NFnGamma:   "Gamma",
+	NFnIsPrime: "IsPrime",
+	NPercent:   "Percent",
+	NFactorial: "Factorial",
+	NBitNot:    "BitNot",
+
+	NFnBinom: "Binom",
+	NFnPerm:  "Perm",
+	NFnGcd:   "Gcd",
+	NFnLcm:   "Lcm",
+	NFnMod:   "IntMod",
+}
+
+// String converts a node type to a string. The name of a node type doesn't
+// change, when new node types get added.
+func (t NodeType) String() string {
+	if t < NodeType(len(nodeTypes)) && nodeTypes[t] != "" {
+		return nodeTypes[t]
+	}
+
+	return "Unknown node type"
+}
+
+// NodeTypeFromString returns the node type with the given name. The second
+// return value is false, if there is no node type with that name.
+func NodeTypeFromString(name string) (NodeType, bool) {
+	for t, n := range nodeTypes {
+		if n != "" && n == name {
+			return NodeType(t), true
+		}
+	}
+
+	return NError, false
+}
+
 // CalcVisitor defines the visitor function called when calculation a node.
 type CalcVisitor func(INode) (float64, error)
 
@@ -63,6 +154,15 @@ type INode interface {
 	Calculate(CalcVisitor) (float64, error)
 }
 
+// IConstant defines an interface for a node, that holds an already calculated
+// value, like a constant folded by the optimizer.
+type IConstant interface {
+	INode
+
+	// Constant returns the calculated value.
+	Constant() float64
+}
+
 // IsLiteral returns true if t is a literal.
 func IsLiteral(n INode) bool {
 	return literalBeg < n.GetType() && n.GetType() < literalEnd
@@ -78,6 +178,28 @@ func IsFunction(n INode) bool {
 	return functionBeg < n.GetType() && n.GetType() < functionEnd
 }
 
+// IsBinaryFunction returns true if t is a function with two arguments. The
+// first argument is the left and the second argument the right child node.
+func IsBinaryFunction(n INode) bool {
+	return binaryFunctionBeg < n.GetType() && n.GetType() < functionEnd
+}
+
+// IsNil returns true if n is nil or holds a nil node. A missing child of a
+// Node is a nil *Node, so it isn't equal to nil as an INode.
+func IsNil(n INode) bool {
+	if n == nil {
+		return true
+	}
+
+	node, ok := n.(*Node)
+	return ok && node == nil
+}
+
+// IsEmpty returns true if ast is nil or has no root node.
+func IsEmpty(ast IAST) bool {
+	return ast == nil || IsNil(ast.Root())
+}
+
 // IAST defines an interface for an ast.
 type IAST interface {
 	// Root returns the root node.
@@ -133,19 +255,22 @@ func (n *Node) SetRight(r INode) {
 func (n *Node) Calculate(fn CalcVisitor) (float64, error) { return fn(n) }
 
 // isHigherOperator returns true if operator n is of higher than n2.
-// Order is defined as the following:
-// NAddition, NSubtraction = 0
-// NMultiplication, NDivision = 1
+// Order is defined by Precedence().
 func (n *Node) isHigherOperator(n2 *Node) bool {
-	if n.Type <= NSub && n2.Type <= NSub {
-		return false
-	}
+	return Precedence(n.Type) < Precedence(n2.Type)
+}
 
-	if n.Type > NSub && n2.Type > NSub {
-		return false
+// Precedence returns the precedence of an operator. Operators with a higher
+// precedence get calculated first. Like in Go, the shifts have the same
+// precedence as the multiplication. Order is defined as the following:
+// NAdd, NSub = 0
+// all other operators = 1
+func Precedence(t NodeType) int {
+	if t <= NSub {
+		return 0
 	}
 
-	return n.Type < n2.Type
+	return 1
 }
 
 // AST stores the data of the abstract syntax tree.
@@ -154,8 +279,12 @@ type AST struct {
 	Node *Node
 }
 
-// Root returns the root node.
+// Root returns the root node. Returns nil, if the ast is empty.
 func (a *AST) Root() INode {
+	if a.Node == nil {
+		return nil
+	}
+
 	return a.Node
 }
 
@@ -185,6 +314,12 @@ func getOperatorNodeType(t token.Token) (NodeType, bool) {
 		return NXor, true
 	case token.And:
 		return NAnd, true
+	case token.ShiftLeft:
+		return NShiftLeft, true
+	case token.ShiftRight:
+		return NShiftRight, true
+	case token.UnsignedShiftRight:
+		return NUnsignedShiftRight, true
 	}
 
 	return NInvalidOperator, false
@@ -205,6 +340,12 @@ func getNumberOrVariableNodeType(t token.Token) (NodeType, bool) {
 		return NHex, true
 	case token.Exp:
 		return NExp, true
+	case token.Oct:
+		return NOct, true
+	case token.Sci:
+		return NSci, true
+	case token.HexFloat:
+		return NHexFloat, true
 	case token.Var:
 		return NVar, true
 	}
@@ -231,7 +372,44 @@ func getFunctionNodeType(t token.Token) (NodeType, bool) {
 		return NFnCos, true
 	case token.Tan:
 		return NFnTan, true
+	case token.Gamma:
+		return NFnGamma, true
+	case token.IsPrime:
+		return NFnIsPrime, true
+	case token.Binom:
+		return NFnBinom, true
+	case token.Perm:
+		return NFnPerm, true
+	case token.Gcd:
+		return NFnGcd, true
+	case token.Lcm:
+		return NFnLcm, true
+	case token.IntMod:
+		return NFnMod, true
 	}
 
 	return NInvalidFunction, false
 }
+
+// getPostfixNodeType converts a token type to a node type.
+// The second return value is false, if the token isn't a postfix operator.
+func getPostfixNodeType(t token.Token) (NodeType, bool) {
+	switch t.Type {
+	case token.Percent:
+		return NPercent, true
+	case token.Factorial:
+		return NFactorial, true
+	}
+
+	return NError, false
+}
+
+// getPrefixNodeType converts a token type to a node type.
+// The second return value is false, if the token isn't a prefix operator.
+func getPrefixNodeType(t token.Token) (NodeType, bool) {
+	if t.Type == token.BitNot {
+		return NBitNot, true
+	}
+
+	return NError, false
+}
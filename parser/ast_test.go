@@ -33,6 +33,9 @@ var _ = DescribeTable("IsOperator()",
 	Entry("8", parser.NXor, true),
 	Entry("9", parser.NAnd, true),
 	Entry("10", parser.NFnSqrt, false),
+	Entry("11", parser.NShiftLeft, true),
+	Entry("12", parser.NUnsignedShiftRight, true),
+	Entry("13", parser.NBitNot, false),
 )
 
 var _ = DescribeTable("IsFunction()",
@@ -46,3 +49,51 @@ var _ = DescribeTable("IsFunction()",
 	Entry("4", parser.NFnTan, true),
 	Entry("5", parser.NFnSqrt, true),
 )
+
+var _ = DescribeTable("IsNil()",
+	func(n parser.INode, exp bool) {
+		Expect(parser.IsNil(n)).To(Equal(exp))
+	},
+	Entry("nil", nil, true),
+	Entry("nil node", (*parser.Node)(nil), true),
+	Entry("missing child", (&parser.Node{Type: parser.NAdd}).Left(), true),
+	Entry("node", &parser.Node{Type: parser.NInt, Value: "1"}, false),
+)
+
+var _ = DescribeTable("IsEmpty()",
+	func(ast parser.IAST, exp bool) {
+		Expect(parser.IsEmpty(ast)).To(Equal(exp))
+		if exp && ast != nil {
+			Expect(ast.Root() == nil).To(BeTrue())
+		}
+	},
+	Entry("nil", nil, true),
+	Entry("empty ast", &parser.AST{}, true),
+	Entry("ast", &parser.AST{Node: &parser.Node{Type: parser.NInt, Value: "1"}}, false),
+)
+
+var _ = DescribeTable("NodeType.String()",
+	func(nodeType parser.NodeType, exp string) {
+		Expect(nodeType.String()).To(Equal(exp))
+	},
+	Entry("1", parser.NError, "Error"),
+	Entry("2", parser.NInt, "Int"),
+	Entry("3", parser.NVar, "Var"),
+	Entry("4", parser.NAdd, "Add"),
+	Entry("5", parser.NFnTan, "Tan"),
+	Entry("6", parser.NodeType(1000), "Unknown node type"),
+)
+
+var _ = DescribeTable("NodeTypeFromString()",
+	func(name string, expType parser.NodeType, expOk bool) {
+		nodeType, ok := parser.NodeTypeFromString(name)
+		Expect(nodeType).To(Equal(expType))
+		Expect(ok).To(Equal(expOk))
+	},
+	Entry("1", "Error", parser.NError, true),
+	Entry("2", "Dec", parser.NDec, true),
+	Entry("3", "Mult", parser.NMult, true),
+	Entry("4", "Sqrt", parser.NFnSqrt, true),
+	Entry("5", "sqrt", parser.NError, false),
+	Entry("6", "", parser.NError, false),
+)
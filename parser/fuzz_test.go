@@ -0,0 +1,131 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/relnod/calcgo/lexer"
+	"github.com/relnod/calcgo/parser"
+	"github.com/relnod/calcgo/token"
+)
+
+var fuzzOptions = parser.Options{
+	MaxInputBytes: 256,
+	MaxDepth:      8,
+	MaxTokens:     64,
+}
+
+// countNodes returns the number of nodes and the depth of the tree.
+func countNodes(n parser.INode) (int, int) {
+	if parser.IsNil(n) {
+		return 0, 0
+	}
+
+	leftNodes, leftDepth := countNodes(n.Left())
+	rightNodes, rightDepth := countNodes(n.Right())
+	if rightDepth > leftDepth {
+		leftDepth = rightDepth
+	}
+
+	return leftNodes + rightNodes + 1, leftDepth + 1
+}
+
+// recordingReader records, how far the parser read the input.
+type recordingReader struct {
+	*lexer.Lexer
+	tooLong bool
+	end     int
+}
+
+// Read returns the next token of the lexer and records it.
+func (r *recordingReader) Read() token.Token {
+	t := r.Lexer.Read()
+	if t.Type == token.InputTooLong {
+		r.tooLong = true
+	} else if t.End > r.end {
+		r.end = t.End
+	}
+
+	return t
+}
+
+// contains returns true, if errs contains err.
+func contains(errs []error, err error) bool {
+	for _, e := range errs {
+		if e == err {
+			return true
+		}
+	}
+
+	return false
+}
+
+func FuzzParseWithOptions(f *testing.F) {
+	f.Add("(1 + 2) * 3")
+	f.Add("sqrt(sin(a) / 0x1F) % 0b101")
+	f.Add("~(a << 2) >>> ~~b! >> 1")
+	f.Add(strings.Repeat("(", 20) + "1" + strings.Repeat(")", 20))
+	f.Add(strings.Repeat("sqrt(", 20) + "1")
+	f.Add(strings.Repeat("1 + ", 100) + "1")
+	f.Add(strings.Repeat("9", 1000))
+	f.Add("1, " + strings.Repeat("2", 300))
+	f.Add("1) + " + strings.Repeat("2", 300))
+
+	f.Fuzz(func(t *testing.T, str string) {
+		// The same setup as parser.ParseWithOptions, but the reader records,
+		// how far the parser read.
+		l := lexer.NewLexerFromString(str)
+		l.SetMaxInputBytes(fuzzOptions.MaxInputBytes)
+		r := &recordingReader{Lexer: l}
+		ast, errs := parser.ParseFromReaderWithOptions(r, fuzzOptions)
+
+		nodes, _ := countNodes(ast.Node)
+		if nodes > fuzzOptions.MaxTokens {
+			t.Errorf("got %d nodes, expected at most %d", nodes, fuzzOptions.MaxTokens)
+		}
+
+		if r.end > fuzzOptions.MaxInputBytes {
+			t.Errorf("read a token up to byte %d, expected at most %d", r.end, fuzzOptions.MaxInputBytes)
+		}
+
+		if r.tooLong != contains(errs, parser.ErrorMaxInputBytesExceeded) {
+			t.Errorf("input too long: %v, but got errors %v", r.tooLong, errs)
+		}
+		if len(str) <= fuzzOptions.MaxInputBytes && r.tooLong {
+			t.Errorf("input of length %d was rejected", len(str))
+		}
+
+		// The parser stops at an unexpected closing bracket or comma and when
+		// a limit is exceeded, before it reads the rest of the input. This
+		// is fine, because the input gets rejected anyway and only the
+		// bytes up to the limit got read.
+		if len(str) > fuzzOptions.MaxInputBytes && !r.tooLong {
+			if !contains(errs, parser.ErrorUnexpectedClosingBracket) && !contains(errs, parser.ErrorUnexpectedComma) &&
+				!contains(errs, parser.ErrorMaxTokensExceeded) && !contains(errs, parser.ErrorMaxDepthExceeded) {
+				t.Errorf("input of length %d was accepted: %v", len(str), errs)
+			}
+		}
+	})
+}
+
+func FuzzParseWithOptionsNesting(f *testing.F) {
+	f.Add(0, "1")
+	f.Add(8, "1 + 1")
+	f.Add(9, "sqrt(1)")
+	f.Add(50, "a")
+
+	f.Fuzz(func(t *testing.T, depth int, inner string) {
+		if depth < 0 || depth > 10000 {
+			return
+		}
+
+		str := strings.Repeat("(", depth) + inner + strings.Repeat(")", depth)
+		_, errs := parser.ParseWithOptions(str, parser.Options{MaxDepth: fuzzOptions.MaxDepth})
+
+		if depth > fuzzOptions.MaxDepth {
+			if len(errs) == 0 || errs[len(errs)-1] != parser.ErrorMaxDepthExceeded {
+				t.Errorf("nesting depth %d was accepted: %v", depth, errs)
+			}
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"errors"
+	"io"
+
+	"github.com/relnod/calcgo/lexer"
+	"github.com/relnod/calcgo/token"
+)
+
+// Errors, that occur when a limit of the options is exceeded
+var (
+	ErrorMaxInputBytesExceeded = errors.New("Error: Maximum input length exceeded")
+	ErrorMaxDepthExceeded      = errors.New("Error: Maximum nesting depth exceeded")
+	ErrorMaxTokensExceeded     = errors.New("Error: Maximum number of tokens exceeded")
+)
+
+// Options defines limits, that get enforced during parsing. A limit with the
+// value 0 is disabled. Options also define how percentages get calculated.
+type Options struct {
+	// MaxInputBytes limits the length of the input. It gets enforced by the
+	// lexer, so no more than MaxInputBytes+1 bytes get read from the input.
+	MaxInputBytes int
+
+	// MaxDepth limits the nesting depth of brackets and function arguments.
+	MaxDepth int
+
+	// MaxTokens limits the number of tokens.
+	MaxTokens int
+
+	// Percent defines how percentages get calculated (see ApplyPercentMode).
+	// The default is PercentOfHundred.
+	Percent PercentMode
+}
+
+// limiter enforces the options during parsing. It is shared between a parser
+// and all of its sub parsers.
+type limiter struct {
+	options Options
+	tokens  int
+	aborted bool
+}
+
+// ParseWithOptions behaves like Parse, but enforces the given options. Parsing
+// stops as soon as a limit is exceeded.
+func ParseWithOptions(str string, options Options) (AST, []error) {
+	if len(str) == 0 {
+		return AST{}, nil
+	}
+
+	l := lexer.NewLexerFromString(str)
+	l.SetMaxInputBytes(options.MaxInputBytes)
+
+	return ParseFromReaderWithOptions(l, options)
+}
+
+// ParseFromIOReaderWithOptions parses the input of an io.Reader and enforces
+// the given options.
+func ParseFromIOReaderWithOptions(r io.Reader, options Options) (AST, []error) {
+	l := lexer.NewLexer(r)
+	l.SetMaxInputBytes(options.MaxInputBytes)
+
+	return ParseFromReaderWithOptions(l, options)
+}
+
+// ParseFromReaderWithOptions parses a stream of token retrieved from a token
+// reader and enforces the given options. The maximum input length is only
+// enforced, if the token reader was set up accordingly (see
+// lexer.Lexer.SetMaxInputBytes).
+func ParseFromReaderWithOptions(reader token.Reader, options Options) (AST, []error) {
+	p := &Parser{reader: reader, limiter: &limiter{options: options}}
+	p.run()
+
+	if n, ok := ApplyPercentMode(p.topNode, options.Percent).(*Node); ok {
+		p.topNode = n
+	}
+
+	return AST{p.topNode}, append(p.lexErrors(), p.errors...)
+}
+
+// abort stops the parser and all of its sub parsers with the given error.
+func (p *Parser) abort(err error) {
+	p.pushError(err)
+	p.limiter.aborted = true
+}
+
+// countToken counts the current token. Returns false if the maximum number of
+// tokens is exceeded.
+func (p *Parser) countToken() bool {
+	p.limiter.tokens++
+
+	max := p.limiter.options.MaxTokens
+	return max <= 0 || p.limiter.tokens <= max
+}
+
+// canNest returns false, if the maximum nesting depth is reached.
+func (p *Parser) canNest() bool {
+	max := p.limiter.options.MaxDepth
+	return max <= 0 || p.depth < max
+}
@@ -0,0 +1,96 @@
+package parser_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/parser"
+)
+
+// infiniteReader is an io.Reader, that never ends.
+type infiniteReader struct {
+	read int
+}
+
+func (r *infiniteReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = '1'
+	}
+	r.read += len(p)
+	return len(p), nil
+}
+
+var _ = Describe("ParseWithOptions()", func() {
+	DescribeTable("limits",
+		func(str string, options parser.Options, expErrs []error) {
+			_, errs := parser.ParseWithOptions(str, options)
+			Expect(errs).To(Equal(expErrs))
+		},
+		Entry("no limits", "((1 + 2) * 3)", parser.Options{}, nil),
+		Entry("within limits", "((1 + 2) * 3)", parser.Options{
+			MaxInputBytes: 13, MaxDepth: 2, MaxTokens: 9,
+		}, nil),
+		Entry("input too long", "((1 + 2) * 3)", parser.Options{MaxInputBytes: 12},
+			[]error{parser.ErrorMaxInputBytesExceeded}),
+		Entry("too deeply nested", "((1 + 2) * 3)", parser.Options{MaxDepth: 1},
+			[]error{parser.ErrorMaxDepthExceeded}),
+		Entry("too deeply nested function", "sqrt(sqrt(1))", parser.Options{MaxDepth: 1},
+			[]error{parser.ErrorMaxDepthExceeded}),
+		Entry("too many tokens", "((1 + 2) * 3)", parser.Options{MaxTokens: 8},
+			[]error{parser.ErrorMaxTokensExceeded}),
+		Entry("deep nesting", strings.Repeat("(", 100000)+"1", parser.Options{MaxDepth: 100},
+			[]error{parser.ErrorMaxDepthExceeded}),
+	)
+
+	It("stops reading from an infinite reader", func() {
+		r := &infiniteReader{}
+		_, errs := parser.ParseFromIOReaderWithOptions(r, parser.Options{MaxInputBytes: 100})
+		Expect(errs).To(Equal([]error{parser.ErrorMaxInputBytesExceeded}))
+		Expect(r.read).To(BeNumerically("<=", 101))
+	})
+
+	It("returns read errors", func() {
+		err := errors.New("read failed")
+		r := io.MultiReader(strings.NewReader("1 + 2"), iotest.ErrReader(err))
+		_, errs := parser.ParseFromIOReaderWithOptions(r, parser.Options{})
+		Expect(errs).To(Equal([]error{err}))
+	})
+
+	It("parses large inputs", func() {
+		r := strings.NewReader(strings.Repeat("1 + ", 500000) + "1")
+		ast, errs := parser.ParseFromIOReaderWithOptions(r, parser.Options{})
+		Expect(errs).To(BeNil())
+		Expect(ast.Node).NotTo(BeNil())
+	})
+
+	It("returns the same ast as Parse()", func() {
+		str := "(1 + a) * sqrt(2 / b) - 3"
+		ast1, errs1 := parser.Parse(str)
+		ast2, errs2 := parser.ParseWithOptions(str, parser.Options{
+			MaxInputBytes: 100, MaxDepth: 10, MaxTokens: 100,
+		})
+		Expect(ast2).To(Equal(ast1))
+		Expect(errs2).To(Equal(errs1))
+	})
+
+	DescribeTable("percent modes",
+		func(str string, mode parser.PercentMode, expStr string) {
+			ast, errs := parser.ParseWithOptions(str, parser.Options{Percent: mode})
+			Expect(errs).To(BeNil())
+			expAST, _ := parser.Parse(expStr)
+			Expect(ast).To(Equal(expAST))
+		},
+		Entry("of hundred", "200 + 15%", parser.PercentOfHundred, "200 + 15%"),
+		Entry("of value with addition", "200 + 15%", parser.PercentOfValue, "(200 * (100 + 15))%"),
+		Entry("of value with subtraction", "a - b%", parser.PercentOfValue, "(a * (100 - b))%"),
+		Entry("of value with multiplication", "a * 20%", parser.PercentOfValue, "a * 20%"),
+		Entry("of value with brackets", "(1 + 2)%", parser.PercentOfValue, "(1 + 2)%"),
+		Entry("of value chained", "a + 10% - 5%", parser.PercentOfValue, "((a * (100 + 10))% * (100 - 5))%"),
+	)
+})
@@ -17,8 +17,12 @@ type Parser struct {
 	currToken token.Token
 	topNode   *Node
 	current   *Node
+	parent    *Node
+	prefixes  []NodeType
 	errors    []error
 	nested    bool
+	depth     int
+	limiter   *limiter
 }
 
 // Errors, that can occur during parsing
@@ -28,6 +32,9 @@ var (
 	ErrorUnknownFunction          = errors.New("Error: Unknown Function")
 	ErrorMissingClosingBracket    = errors.New("Error: Missing closing bracket")
 	ErrorUnexpectedClosingBracket = errors.New("Error: Unexpected closing bracket")
+	ErrorReadFailed               = errors.New("Error: Failed to read input")
+	ErrorUnexpectedComma          = errors.New("Error: Unexpected comma")
+	ErrorWrongNumberOfArguments   = errors.New("Error: Wrong number of function arguments")
 )
 
 // Parse parses a string to an ast
@@ -87,10 +94,10 @@ func ParseTokens(tokens []token.Token) (AST, []error) {
 
 // ParseFromReader parses a stream of token retrieved from a token reader.
 func ParseFromReader(reader token.Reader) (AST, []error) {
-	p := &Parser{reader: reader}
+	p := &Parser{reader: reader, limiter: &limiter{}}
 	p.run()
 
-	return AST{p.topNode}, p.errors
+	return AST{p.topNode}, append(p.lexErrors(), p.errors...)
 }
 
 // run runs the parser state machine.
@@ -103,11 +110,17 @@ func (p *Parser) run() {
 
 		state = state(p)
 	}
+
+	p.applyPrefixes()
 }
 
 // next retrieves the next token from the token. If the lexer is finished next
 // returns false. Otherwise returns true.
 func (p *Parser) next() bool {
+	if p.limiter.aborted {
+		return false
+	}
+
 	p.currToken = p.reader.Read()
 
 	if p.currToken.Type == token.EOF {
@@ -117,9 +130,73 @@ func (p *Parser) next() bool {
 		return false
 	}
 
+	if p.currToken.Type == token.InputTooLong {
+		p.abort(ErrorMaxInputBytesExceeded)
+		return false
+	}
+
+	if p.currToken.Type == token.ReadError {
+		p.abort(p.readErr())
+		return false
+	}
+
+	if !p.countToken() {
+		p.abort(ErrorMaxTokensExceeded)
+		return false
+	}
+
 	return true
 }
 
+// readErr returns the read error of the token reader. Returns
+// ErrorReadFailed, if the token reader doesn't report read errors.
+func (p *Parser) readErr() error {
+	// The read error is the last error of the lexer, because lexing stops
+	// after it.
+	if r, ok := p.reader.(interface{ Errors() []error }); ok {
+		if errors := r.Errors(); len(errors) > 0 {
+			return errors[len(errors)-1]
+		}
+	}
+
+	return ErrorReadFailed
+}
+
+// lexErrors returns the errors of the lexer, that concern numbers, like
+// unterminated numbers, misplaced digit separators or invalid digits. Such
+// numbers can still be valid tokens, so the errors only show up here. The
+// errors are only returned, if the whole input was read, because the lexer
+// might still be running otherwise.
+func (p *Parser) lexErrors() []error {
+	if p.currToken.Type != token.EOF {
+		return nil
+	}
+
+	r, ok := p.reader.(interface{ Errors() []error })
+	if !ok {
+		return nil
+	}
+
+	var errors []error
+	for _, err := range r.Errors() {
+		if e, ok := err.(*lexer.Error); ok && isNumberError(e.Err) {
+			errors = append(errors, err)
+		}
+	}
+
+	return errors
+}
+
+// isNumberError returns true, if err is a lexical error of a number.
+func isNumberError(err error) bool {
+	switch err {
+	case lexer.ErrorUnterminatedNumber, lexer.ErrorInvalidSeparator, lexer.ErrorInvalidDigit:
+		return true
+	}
+
+	return false
+}
+
 // pushError adds an error to the parser error list.
 func (p *Parser) pushError(err error) {
 	p.errors = append(p.errors, err)
@@ -162,33 +239,81 @@ func (p *Parser) newFunctionNode() *Node {
 	return &Node{nt, p.currToken.Value, nil, nil}
 }
 
-// subParse creates another parser and runs it until a closing bracket appears.
-func (p *Parser) subParse() (*Node, []error) {
+// subParse creates another parser and runs it until a closing bracket or a
+// comma appears. Returns the type of the token, that stopped the sub parser.
+func (p *Parser) subParse() (*Node, []error, token.Type) {
+	if !p.canNest() {
+		p.abort(ErrorMaxDepthExceeded)
+		return nil, nil, token.EOF
+	}
+
 	p2 := &Parser{
 		reader:    p.reader,
 		currToken: token.Token{},
-		topNode:   p.topNode,
+		topNode:   nil,
 		current:   nil,
+		parent:    nil,
 		errors:    nil,
 		nested:    true,
+		depth:     p.depth + 1,
+		limiter:   p.limiter,
 	}
 
 	p2.run()
 
-	return p2.topNode, p2.errors
+	return p2.topNode, p2.errors, p2.currToken.Type
 }
 
-// subParseFunctionArgument parses the argument of a function.
-func (p *Parser) subParseFunctionArgument(n *Node) {
+// subParseList runs a sub parser for each element of a comma separated list,
+// until a closing bracket appears. Returns the type of the token, that stopped
+// the last sub parser.
+func (p *Parser) subParseList() ([]*Node, token.Type) {
+	var nodes []*Node
+	for {
+		n, errors, end := p.subParse()
+		nodes = append(nodes, n)
+		p.pushErrors(errors)
+
+		if end != token.Comma {
+			return nodes, end
+		}
+	}
+}
+
+// subParseBrackets parses the content of brackets.
+func (p *Parser) subParseBrackets() *Node {
+	nodes, _ := p.subParseList()
+	if len(nodes) > 1 {
+		p.pushError(ErrorUnexpectedComma)
+	}
+
+	return nodes[0]
+}
+
+// subParseFunctionArguments parses the arguments of a function. The first
+// argument becomes the left and the second argument the right child node.
+func (p *Parser) subParseFunctionArguments(n *Node) {
 	p.current = n
-	n, errors := p.subParse()
-	p.current.LeftChild = n
-	p.pushErrors(errors)
+	args, end := p.subParseList()
+	p.current.LeftChild = args[0]
+
+	arity := 1
+	if IsBinaryFunction(n) {
+		arity = 2
+		if len(args) > 1 {
+			p.current.RightChild = args[1]
+		}
+	}
+
+	if end == token.ParenR && IsFunction(n) && len(args) != arity {
+		p.pushError(ErrorWrongNumberOfArguments)
+	}
 }
 
 // setFirstTopNode sets the first top node
 func (p *Parser) setFirstTopNode(n *Node) {
 	p.topNode = n
+	p.parent = nil
 }
 
 // setNewTopNode sets a new top node.
@@ -229,6 +354,55 @@ func (p *Parser) setNewRightChild(n *Node) {
 //
 func (p *Parser) addNewRightChild(n *Node) {
 	p.current.RightChild = n
+	p.parent = p.current
+}
+
+// wrapValue replaces the last parsed value with a new node of type t, that has
+// the value as its argument. This is used for postfix and prefix operators.
+// The last value is either the top node or the right child of its parent node.
+//
+//    a            a
+//   / \   =>    / \
+//  b   c        b   %
+//                  /
+//                 c
+//
+func (p *Parser) wrapValue(t NodeType) {
+	if p.parent == nil {
+		p.topNode = &Node{t, "", p.topNode, nil}
+		return
+	}
+
+	p.parent.RightChild = &Node{t, "", p.parent.RightChild, nil}
+}
+
+// parsePrefixes collects the prefix operators in front of a value. Returns
+// false, if the input ends before the value.
+func (p *Parser) parsePrefixes() bool {
+	for {
+		t, ok := getPrefixNodeType(p.currToken)
+		if !ok {
+			return true
+		}
+		p.prefixes = append(p.prefixes, t)
+
+		if !p.next() {
+			if p.currToken.Type == token.EOF {
+				p.pushError(ErrorExpectedNumberOrVariable)
+			}
+			p.prefixes = nil
+			return false
+		}
+	}
+}
+
+// applyPrefixes wraps the last parsed value with the pending prefix operators.
+// This happens after the postfix operators got applied, so "~a!" is "~(a!)".
+func (p *Parser) applyPrefixes() {
+	for i := len(p.prefixes) - 1; i >= 0; i-- {
+		p.wrapValue(p.prefixes[i])
+	}
+	p.prefixes = nil
 }
 
 // parseStart is the start state of the parser machine.
@@ -240,16 +414,20 @@ func (p *Parser) addNewRightChild(n *Node) {
 //  - TInteger
 //  - TDecimal
 //  - TVariable
+//  - TBitNot
 //
 // The following states can follow:
 //  - parseOperator
 //  - parseOperatorAfterRightBracket
 //
 func parseStart(p *Parser) parseState {
+	if !p.parsePrefixes() {
+		return nil
+	}
+
 	if p.currToken.Type == token.ParenL {
-		n, errors := p.subParse()
+		n := p.subParseBrackets()
 		p.setFirstTopNode(n)
-		p.pushErrors(errors)
 
 		return parseOperatorAfterRightBracket
 	}
@@ -258,7 +436,7 @@ func parseStart(p *Parser) parseState {
 		n := p.newFunctionNode()
 		p.setFirstTopNode(n)
 		p.current = n
-		p.subParseFunctionArgument(n)
+		p.subParseFunctionArguments(n)
 
 		return parseOperator
 	}
@@ -277,16 +455,20 @@ func parseStart(p *Parser) parseState {
 //  - TInteger
 //  - TDecimal
 //  - TVariable
+//  - TBitNot
 //
 // The following states can follow:
 //  - parseOperator
 //  - parseOperatorAfterRightBracket
 //
 func parseValue(p *Parser) parseState {
+	if !p.parsePrefixes() {
+		return nil
+	}
+
 	if p.currToken.Type == token.ParenL {
-		n, errors := p.subParse()
+		n := p.subParseBrackets()
 		p.addNewRightChild(n)
-		p.pushErrors(errors)
 
 		return parseOperator
 	}
@@ -294,7 +476,7 @@ func parseValue(p *Parser) parseState {
 	if p.currToken.IsFunction() {
 		n := p.newFunctionNode()
 		p.addNewRightChild(n)
-		p.subParseFunctionArgument(n)
+		p.subParseFunctionArguments(n)
 
 		return parseOperator
 	}
@@ -308,12 +490,21 @@ func parseValue(p *Parser) parseState {
 //
 // Expects one of these tokens:
 //  - TRightBracket
+//  - TComma
 //  - TOperator*
+//  - TPercent
+//  - TFactorial
 //
 // The following states can follow:
 //  - parseValue
 //
 func parseOperator(p *Parser) parseState {
+	if t, ok := getPostfixNodeType(p.currToken); ok {
+		p.wrapValue(t)
+		return parseOperator
+	}
+	p.applyPrefixes()
+
 	if p.currToken.Type == token.ParenR {
 		if !p.nested {
 			p.pushError(ErrorUnexpectedClosingBracket)
@@ -321,6 +512,13 @@ func parseOperator(p *Parser) parseState {
 		return nil
 	}
 
+	if p.currToken.Type == token.Comma {
+		if !p.nested {
+			p.pushError(ErrorUnexpectedComma)
+		}
+		return nil
+	}
+
 	node := p.newOperatorNode()
 	// Handle 'multiplication and division before addition and subtraction' rule
 	if IsOperator(p.topNode) && p.topNode.isHigherOperator(node) {
@@ -337,12 +535,21 @@ func parseOperator(p *Parser) parseState {
 //
 // Expects one of these tokens:
 //  - TRightBracket
+//  - TComma
 //  - TOperator*
+//  - TPercent
+//  - TFactorial
 //
 // The following states can follow:
 //  - parseValue
 //
 func parseOperatorAfterRightBracket(p *Parser) parseState {
+	if t, ok := getPostfixNodeType(p.currToken); ok {
+		p.wrapValue(t)
+		return parseOperatorAfterRightBracket
+	}
+	p.applyPrefixes()
+
 	if p.currToken.Type == token.ParenR {
 		if !p.nested {
 			p.pushError(ErrorUnexpectedClosingBracket)
@@ -350,6 +557,13 @@ func parseOperatorAfterRightBracket(p *Parser) parseState {
 		return nil
 	}
 
+	if p.currToken.Type == token.Comma {
+		if !p.nested {
+			p.pushError(ErrorUnexpectedComma)
+		}
+		return nil
+	}
+
 	node := p.newOperatorNode()
 	p.setNewTopNode(node)
 
@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	"github.com/relnod/calcgo/lexer"
 	"github.com/relnod/calcgo/parser"
 )
 
@@ -101,6 +102,9 @@ var _ = Describe("Parser", func() {
 		Entry("or", "|", parser.NOr, nil),
 		Entry("xor", "^", parser.NXor, nil),
 		Entry("and", "&", parser.NAnd, nil),
+		Entry("shift left", "<<", parser.NShiftLeft, nil),
+		Entry("shift right", ">>", parser.NShiftRight, nil),
+		Entry("unsigned shift right", ">>>", parser.NUnsignedShiftRight, nil),
 		PEntry("invalid", "{", parser.NInvalidOperator, []error{parser.ErrorExpectedOperator}),
 	)
 
@@ -317,4 +321,299 @@ var _ = Describe("Parser", func() {
 		Entry("3", "-", "*", parser.NMult, parser.NSub),
 		Entry("4", "-", "/", parser.NDiv, parser.NSub),
 	)
+
+	DescribeTable("percent", test,
+		Entry("number", "15%", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NPercent,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "15"},
+			},
+		}, nil),
+		Entry("after operator", "200 + 15%", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NAdd,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "200"},
+				RightChild: &parser.Node{
+					Type:      parser.NPercent,
+					LeftChild: &parser.Node{Type: parser.NInt, Value: "15"},
+				},
+			},
+		}, nil),
+		Entry("after higher operator", "1 + a * 20%", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NAdd,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "1"},
+				RightChild: &parser.Node{
+					Type:      parser.NMult,
+					LeftChild: &parser.Node{Type: parser.NVar, Value: "a"},
+					RightChild: &parser.Node{
+						Type:      parser.NPercent,
+						LeftChild: &parser.Node{Type: parser.NInt, Value: "20"},
+					},
+				},
+			},
+		}, nil),
+		Entry("before operator", "15% * 2", parser.AST{
+			Node: &parser.Node{
+				Type: parser.NMult,
+				LeftChild: &parser.Node{
+					Type:      parser.NPercent,
+					LeftChild: &parser.Node{Type: parser.NInt, Value: "15"},
+				},
+				RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+			},
+		}, nil),
+		Entry("brackets", "(1 + 2)%", parser.AST{
+			Node: &parser.Node{
+				Type: parser.NPercent,
+				LeftChild: &parser.Node{
+					Type:       parser.NAdd,
+					LeftChild:  &parser.Node{Type: parser.NInt, Value: "1"},
+					RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+				},
+			},
+		}, nil),
+		Entry("function", "sqrt(4)%", parser.AST{
+			Node: &parser.Node{
+				Type: parser.NPercent,
+				LeftChild: &parser.Node{
+					Type:      parser.NFnSqrt,
+					LeftChild: &parser.Node{Type: parser.NInt, Value: "4"},
+				},
+			},
+		}, nil),
+		Entry("modulo", "15%4", parser.AST{
+			Node: &parser.Node{
+				Type:       parser.NMod,
+				LeftChild:  &parser.Node{Type: parser.NInt, Value: "15"},
+				RightChild: &parser.Node{Type: parser.NInt, Value: "4"},
+			},
+		}, nil),
+	)
+
+	DescribeTable("factorial", test,
+		Entry("number", "5!", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NFactorial,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "5"},
+			},
+		}, nil),
+		Entry("after operator", "2 * n!", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NMult,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "2"},
+				RightChild: &parser.Node{
+					Type:      parser.NFactorial,
+					LeftChild: &parser.Node{Type: parser.NVar, Value: "n"},
+				},
+			},
+		}, nil),
+		Entry("brackets", "(n - 1)!", parser.AST{
+			Node: &parser.Node{
+				Type: parser.NFactorial,
+				LeftChild: &parser.Node{
+					Type:       parser.NSub,
+					LeftChild:  &parser.Node{Type: parser.NVar, Value: "n"},
+					RightChild: &parser.Node{Type: parser.NInt, Value: "1"},
+				},
+			},
+		}, nil),
+	)
+
+	DescribeTable("shifts", test,
+		Entry("before addition", "1 + a << 2", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NAdd,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "1"},
+				RightChild: &parser.Node{
+					Type:       parser.NShiftLeft,
+					LeftChild:  &parser.Node{Type: parser.NVar, Value: "a"},
+					RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+				},
+			},
+		}, nil),
+		Entry("left associative", "a >> 1 & 0xf", parser.AST{
+			Node: &parser.Node{
+				Type: parser.NAnd,
+				LeftChild: &parser.Node{
+					Type:       parser.NShiftRight,
+					LeftChild:  &parser.Node{Type: parser.NVar, Value: "a"},
+					RightChild: &parser.Node{Type: parser.NInt, Value: "1"},
+				},
+				RightChild: &parser.Node{Type: parser.NHex, Value: "0xf"},
+			},
+		}, nil),
+	)
+
+	DescribeTable("bitwise not", test,
+		Entry("number", "~5", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NBitNot,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "5"},
+			},
+		}, nil),
+		Entry("before operator", "~a & b", parser.AST{
+			Node: &parser.Node{
+				Type: parser.NAnd,
+				LeftChild: &parser.Node{
+					Type:      parser.NBitNot,
+					LeftChild: &parser.Node{Type: parser.NVar, Value: "a"},
+				},
+				RightChild: &parser.Node{Type: parser.NVar, Value: "b"},
+			},
+		}, nil),
+		Entry("after operator", "1 + ~~a", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NAdd,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "1"},
+				RightChild: &parser.Node{
+					Type: parser.NBitNot,
+					LeftChild: &parser.Node{
+						Type:      parser.NBitNot,
+						LeftChild: &parser.Node{Type: parser.NVar, Value: "a"},
+					},
+				},
+			},
+		}, nil),
+		Entry("brackets", "~(a | 1) << 2", parser.AST{
+			Node: &parser.Node{
+				Type: parser.NShiftLeft,
+				LeftChild: &parser.Node{
+					Type: parser.NBitNot,
+					LeftChild: &parser.Node{
+						Type:       parser.NOr,
+						LeftChild:  &parser.Node{Type: parser.NVar, Value: "a"},
+						RightChild: &parser.Node{Type: parser.NInt, Value: "1"},
+					},
+				},
+				RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+			},
+		}, nil),
+		Entry("function", "2 * ~sqrt(a)", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NMult,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "2"},
+				RightChild: &parser.Node{
+					Type: parser.NBitNot,
+					LeftChild: &parser.Node{
+						Type:      parser.NFnSqrt,
+						Value:     "",
+						LeftChild: &parser.Node{Type: parser.NVar, Value: "a"},
+					},
+				},
+			},
+		}, nil),
+		Entry("postfix operators first", "~n!", parser.AST{
+			Node: &parser.Node{
+				Type: parser.NBitNot,
+				LeftChild: &parser.Node{
+					Type:      parser.NFactorial,
+					LeftChild: &parser.Node{Type: parser.NVar, Value: "n"},
+				},
+			},
+		}, nil),
+		Entry("missing value", "1 + ~", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NAdd,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "1"},
+			},
+		}, []error{parser.ErrorExpectedNumberOrVariable}),
+	)
+
+	DescribeTable("functions with two arguments", test,
+		Entry("binom", "binom(n, 2)", parser.AST{
+			Node: &parser.Node{
+				Type:       parser.NFnBinom,
+				LeftChild:  &parser.Node{Type: parser.NVar, Value: "n"},
+				RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+			},
+		}, nil),
+		Entry("expressions as arguments", "gcd((a + 1) * 2, lcm(b, 3)) - 1", parser.AST{
+			Node: &parser.Node{
+				Type: parser.NSub,
+				LeftChild: &parser.Node{
+					Type: parser.NFnGcd,
+					LeftChild: &parser.Node{
+						Type: parser.NMult,
+						LeftChild: &parser.Node{
+							Type:       parser.NAdd,
+							LeftChild:  &parser.Node{Type: parser.NVar, Value: "a"},
+							RightChild: &parser.Node{Type: parser.NInt, Value: "1"},
+						},
+						RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+					},
+					RightChild: &parser.Node{
+						Type:       parser.NFnLcm,
+						LeftChild:  &parser.Node{Type: parser.NVar, Value: "b"},
+						RightChild: &parser.Node{Type: parser.NInt, Value: "3"},
+					},
+				},
+				RightChild: &parser.Node{Type: parser.NInt, Value: "1"},
+			},
+		}, nil),
+		Entry("missing argument", "perm(5)", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NFnPerm,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "5"},
+			},
+		}, []error{parser.ErrorWrongNumberOfArguments}),
+		Entry("too many arguments", "mod(5, 2, 1)", parser.AST{
+			Node: &parser.Node{
+				Type:       parser.NFnMod,
+				LeftChild:  &parser.Node{Type: parser.NInt, Value: "5"},
+				RightChild: &parser.Node{Type: parser.NInt, Value: "2"},
+			},
+		}, []error{parser.ErrorWrongNumberOfArguments}),
+		Entry("too many arguments for function with one argument", "gamma(5, 2)", parser.AST{
+			Node: &parser.Node{
+				Type:      parser.NFnGamma,
+				LeftChild: &parser.Node{Type: parser.NInt, Value: "5"},
+			},
+		}, []error{parser.ErrorWrongNumberOfArguments}),
+		Entry("comma in brackets", "(1, 2)", parser.AST{
+			Node: &parser.Node{Type: parser.NInt, Value: "1"},
+		}, []error{parser.ErrorUnexpectedComma}),
+		Entry("comma outside of brackets", "1, 2", parser.AST{
+			Node: &parser.Node{Type: parser.NInt, Value: "1"},
+		}, []error{parser.ErrorUnexpectedComma}),
+	)
+
+	DescribeTable("lexical errors of numbers",
+		func(str string, expErrs []error) {
+			_, errs := parser.Parse(str)
+			Expect(errs).To(Equal(expErrs))
+
+			_, errs = parser.ParseWithOptions(str, parser.Options{})
+			Expect(errs).To(Equal(expErrs))
+		},
+		Entry("unterminated exponent", "1e", []error{
+			&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "1e", Start: 0, End: 2, Line: 1, Column: 1},
+		}),
+		Entry("unterminated exponent with sign", "2 * 1e+", []error{
+			&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "1e+", Start: 4, End: 7, Line: 1, Column: 5},
+		}),
+		Entry("hex prefix without digits", "0x", []error{
+			&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "0x", Start: 0, End: 2, Line: 1, Column: 1},
+		}),
+		Entry("octal prefix without digits", "0o", []error{
+			&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "0o", Start: 0, End: 2, Line: 1, Column: 1},
+		}),
+		Entry("single dot", ".", []error{
+			&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: ".", Start: 0, End: 1, Line: 1, Column: 1},
+		}),
+		Entry("double separator", "1__0", []error{
+			&lexer.Error{Err: lexer.ErrorInvalidSeparator, Value: "_", Start: 2, End: 3, Line: 1, Column: 3},
+		}),
+		Entry("trailing separator", "1_", []error{
+			&lexer.Error{Err: lexer.ErrorInvalidSeparator, Value: "_", Start: 1, End: 2, Line: 1, Column: 2},
+		}),
+		Entry("invalid digit", "12a", []error{
+			&lexer.Error{Err: lexer.ErrorInvalidDigit, Value: "a", Start: 2, End: 3, Line: 1, Column: 3},
+			parser.ErrorExpectedNumberOrVariable,
+		}),
+		Entry("lexical errors before parse errors", "(1e", []error{
+			&lexer.Error{Err: lexer.ErrorUnterminatedNumber, Value: "1e", Start: 1, End: 3, Line: 1, Column: 2},
+			parser.ErrorMissingClosingBracket,
+		}),
+	)
 })
@@ -0,0 +1,52 @@
+package parser
+
+// PercentMode defines, how percentages get calculated.
+type PercentMode int
+
+// Percent modes
+const (
+	// PercentOfHundred calculates a percentage as a hundredth of its value
+	// everywhere, so "200 + 15%" is 200.15.
+	PercentOfHundred PercentMode = iota
+
+	// PercentOfValue calculates a percentage, that gets added to or
+	// subtracted from a value, relative to that value, like a pocket
+	// calculator does. So "200 + 15%" is 230 and "200 - 15%" is 170. All
+	// other percentages are a hundredth of their value, so "200 * 15%" is 30.
+	PercentOfValue
+)
+
+// ApplyPercentMode rewrites the percentages of the ast according to the given
+// mode, so that they can be calculated as a hundredth of their value. With
+// PercentOfValue "a + b%" becomes "(a * (100 + b))%" and "a - b%" becomes
+// "(a * (100 - b))%". Multiplying before dividing by 100 keeps results like
+// "200 + 15%" exact. With PercentOfHundred the ast stays the same.
+// The given ast doesn't get modified.
+func ApplyPercentMode(n INode, mode PercentMode) INode {
+	if mode != PercentOfValue {
+		return n
+	}
+
+	return Rewrite(n, func(n INode) INode {
+		t := n.GetType()
+		if t != NAdd && t != NSub {
+			return n
+		}
+		if IsNil(n.Left()) || IsNil(n.Right()) || n.Right().GetType() != NPercent {
+			return n
+		}
+
+		return &Node{
+			Type: NPercent,
+			LeftChild: &Node{
+				Type:      NMult,
+				LeftChild: n.Left(),
+				RightChild: &Node{
+					Type:       t,
+					LeftChild:  &Node{Type: NInt, Value: "100"},
+					RightChild: n.Right().Left(),
+				},
+			},
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package parser
+
+import "sort"
+
+// functionNames maps function node types to their names.
+var functionNames = map[NodeType]string{
+	NFnSqrt: "sqrt",
+	NFnSin:  "sin",
+	NFnCos:  "cos",
+	NFnTan:  "tan",
+
+	NFnGamma:   "gamma",
+	NFnIsPrime: "isprime",
+	NFnBinom:   "binom",
+	NFnPerm:    "perm",
+	NFnGcd:     "gcd",
+	NFnLcm:     "lcm",
+	NFnMod:     "mod",
+}
+
+// Variables returns the sorted names of all variables, that are referenced in
+// the ast. Each name is only returned once.
+//
+// Example:
+//  ast, _ := parser.Parse("b + a * sqrt(b)")
+//  parser.Variables(&ast) // Result: []string{"a", "b"}
+func Variables(ast IAST) []string {
+	return collect(ast, func(n INode) (string, bool) {
+		return n.GetValue(), n.GetType() == NVar
+	})
+}
+
+// Functions returns the sorted names of all functions, that are referenced in
+// the ast. Each name is only returned once.
+//
+// Example:
+//  ast, _ := parser.Parse("sqrt(a) + sin(b) * sqrt(c)")
+//  parser.Functions(&ast) // Result: []string{"sin", "sqrt"}
+func Functions(ast IAST) []string {
+	return collect(ast, func(n INode) (string, bool) {
+		name, ok := functionNames[n.GetType()]
+		return name, ok
+	})
+}
+
+// collect returns the sorted and unique names, that get returned by f for
+// all nodes of the ast.
+func collect(ast IAST, f func(INode) (string, bool)) []string {
+	if ast == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	Inspect(ast.Root(), func(n INode) bool {
+		if n == nil {
+			return false
+		}
+
+		if name, ok := f(n); ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+
+		return true
+	})
+	sort.Strings(names)
+
+	return names
+}
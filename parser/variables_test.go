@@ -0,0 +1,44 @@
+package parser_test
+
+import (
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/parser"
+)
+
+var _ = DescribeTable("Variables()",
+	func(str string, expected []string) {
+		ast, errs := parser.Parse(str)
+		Expect(errs).To(BeNil())
+		Expect(parser.Variables(&ast)).To(Equal(expected))
+	},
+	Entry("empty", "", nil),
+	Entry("no variables", "1 + 2", nil),
+	Entry("single", "a", []string{"a"}),
+	Entry("sorted", "c + b * a", []string{"a", "b", "c"}),
+	Entry("unique", "b + a * b - a", []string{"a", "b"}),
+	Entry("function arguments", "sqrt(x) + sin(y * 2)", []string{"x", "y"}),
+)
+
+var _ = DescribeTable("Functions()",
+	func(str string, expected []string) {
+		ast, errs := parser.Parse(str)
+		Expect(errs).To(BeNil())
+		Expect(parser.Functions(&ast)).To(Equal(expected))
+	},
+	Entry("empty", "", nil),
+	Entry("no functions", "a + 2", nil),
+	Entry("all", "tan(1) + sqrt(2) - sin(3) * cos(4)", []string{"cos", "sin", "sqrt", "tan"}),
+	Entry("unique", "sqrt(a) + sin(b) * sqrt(c)", []string{"sin", "sqrt"}),
+	Entry("nested", "sqrt(sin(a))", []string{"sin", "sqrt"}),
+)
+
+var _ = DescribeTable("Variables() of nil asts",
+	func(ast parser.IAST) {
+		Expect(parser.Variables(ast)).To(BeNil())
+		Expect(parser.Functions(ast)).To(BeNil())
+	},
+	Entry("nil", nil),
+	Entry("empty", &parser.AST{}),
+)
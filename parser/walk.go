@@ -0,0 +1,81 @@
+package parser
+
+// Visitor defines the Visit method, that gets called for each node by Walk.
+// If the visitor w returned by Visit is not nil, Walk visits each child node
+// of n with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(n INode) (w Visitor)
+}
+
+// Walk traverses an ast in depth-first order. It starts by calling
+// v.Visit(n). The left child node gets visited before the right child node.
+// Missing child nodes are skipped, so operators, functions and literals are
+// handled the same way.
+func Walk(v Visitor, n INode) {
+	if IsNil(n) {
+		return
+	}
+
+	if v = v.Visit(n); v == nil {
+		return
+	}
+
+	Walk(v, n.Left())
+	Walk(v, n.Right())
+
+	v.Visit(nil)
+}
+
+// inspector adapts a function to the Visitor interface.
+type inspector func(INode) bool
+
+// Visit calls the inspector function and stops if it returns false.
+func (f inspector) Visit(n INode) Visitor {
+	if f(n) {
+		return f
+	}
+
+	return nil
+}
+
+// Inspect traverses an ast in depth-first order. It calls f(n) for each node.
+// If f returns true, Inspect continues with the child nodes of n, followed by
+// a call of f(nil).
+//
+// Example:
+//  count := 0
+//  parser.Inspect(ast.Root(), func(n parser.INode) bool {
+//  	if n != nil && n.GetType() == parser.NVar {
+//  		count++
+//  	}
+//  	return true
+//  })
+func Inspect(n INode, f func(INode) bool) {
+	Walk(inspector(f), n)
+}
+
+// Rewrite traverses an ast in depth-first order and replaces each node with
+// the result of f. The child nodes get rewritten before their parent node, so
+// f already gets called with the rewritten child nodes. If f returns nil, the
+// node gets removed.
+// The given ast doesn't get modified. If a child node changes, its parent node
+// gets copied as a *Node.
+func Rewrite(n INode, f func(INode) INode) INode {
+	if IsNil(n) {
+		return nil
+	}
+
+	left := Rewrite(n.Left(), f)
+	right := Rewrite(n.Right(), f)
+
+	if left != n.Left() || right != n.Right() {
+		n = &Node{
+			Type:       n.GetType(),
+			Value:      n.GetValue(),
+			LeftChild:  left,
+			RightChild: right,
+		}
+	}
+
+	return f(n)
+}
@@ -0,0 +1,155 @@
+package parser_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/parser"
+)
+
+// countVisitor counts the visited nodes and the calls of Visit(nil).
+type countVisitor struct {
+	nodes int
+	ends  int
+}
+
+func (v *countVisitor) Visit(n parser.INode) parser.Visitor {
+	if n == nil {
+		v.ends++
+		return nil
+	}
+
+	v.nodes++
+	return v
+}
+
+// labels returns the types of all nodes in the order they get inspected.
+func labels(n parser.INode) []string {
+	var l []string
+	parser.Inspect(n, func(n parser.INode) bool {
+		if n != nil {
+			l = append(l, n.GetType().String()+" "+n.GetValue())
+		}
+		return true
+	})
+
+	return l
+}
+
+var _ = Describe("Walk()", func() {
+	It("visits all nodes", func() {
+		ast, errs := parser.Parse("1 + sqrt(a) * 3")
+		Expect(errs).To(BeNil())
+
+		v := &countVisitor{}
+		parser.Walk(v, ast.Root())
+		Expect(v.nodes).To(Equal(6))
+		Expect(v.ends).To(Equal(6))
+	})
+
+	It("handles nil nodes", func() {
+		v := &countVisitor{}
+		parser.Walk(v, nil)
+		parser.Walk(v, (*parser.Node)(nil))
+		Expect(v.nodes).To(Equal(0))
+		Expect(v.ends).To(Equal(0))
+	})
+})
+
+var _ = Describe("Inspect()", func() {
+	It("visits nodes in depth-first order", func() {
+		ast, errs := parser.Parse("1 - sqrt(a) * 3")
+		Expect(errs).To(BeNil())
+
+		Expect(labels(ast.Root())).To(Equal([]string{
+			"Sub ", "Int 1", "Mult ", "Sqrt ", "Var a", "Int 3",
+		}))
+	})
+
+	It("skips child nodes, if false is returned", func() {
+		ast, errs := parser.Parse("1 + sqrt(a + b) * 3")
+		Expect(errs).To(BeNil())
+
+		count := 0
+		parser.Inspect(ast.Root(), func(n parser.INode) bool {
+			if n == nil {
+				return false
+			}
+			count++
+			return n.GetType() != parser.NFnSqrt
+		})
+		Expect(count).To(Equal(5))
+	})
+})
+
+var _ = Describe("Rewrite()", func() {
+	It("replaces nodes", func() {
+		ast, errs := parser.Parse("a + sin(a * b)")
+		Expect(errs).To(BeNil())
+
+		n := parser.Rewrite(ast.Root(), func(n parser.INode) parser.INode {
+			if n.GetType() == parser.NVar && n.GetValue() == "a" {
+				return &parser.Node{Type: parser.NInt, Value: "2"}
+			}
+			return n
+		})
+
+		expected, errs := parser.Parse("2 + sin(2 * b)")
+		Expect(errs).To(BeNil())
+		Expect(n).To(Equal(expected.Root()))
+	})
+
+	It("calls f with rewritten child nodes", func() {
+		ast, errs := parser.Parse("(1 + 2) * 3")
+		Expect(errs).To(BeNil())
+
+		n := parser.Rewrite(ast.Root(), func(n parser.INode) parser.INode {
+			if n.GetType() == parser.NAdd && n.Left().GetValue() == "10" {
+				return &parser.Node{Type: parser.NVar, Value: "x"}
+			}
+			if n.GetType() == parser.NInt && n.GetValue() == "1" {
+				return &parser.Node{Type: parser.NInt, Value: "10"}
+			}
+			return n
+		})
+
+		expected, errs := parser.Parse("x * 3")
+		Expect(errs).To(BeNil())
+		Expect(n).To(Equal(expected.Root()))
+	})
+
+	It("doesn't modify the given ast", func() {
+		ast, errs := parser.Parse("a * (b - c)")
+		Expect(errs).To(BeNil())
+		original, _ := parser.Parse("a * (b - c)")
+
+		parser.Rewrite(ast.Root(), func(n parser.INode) parser.INode {
+			if n.GetType() == parser.NVar {
+				return &parser.Node{Type: parser.NInt, Value: "1"}
+			}
+			return n
+		})
+		Expect(ast).To(Equal(original))
+	})
+
+	It("keeps unchanged nodes", func() {
+		ast, errs := parser.Parse("a * (b - c)")
+		Expect(errs).To(BeNil())
+
+		n := parser.Rewrite(ast.Root(), func(n parser.INode) parser.INode { return n })
+		Expect(n).To(BeIdenticalTo(ast.Root()))
+	})
+
+	It("removes nodes, if nil is returned", func() {
+		ast, errs := parser.Parse("sqrt(a)")
+		Expect(errs).To(BeNil())
+
+		n := parser.Rewrite(ast.Root(), func(n parser.INode) parser.INode {
+			if n.GetType() == parser.NVar {
+				return nil
+			}
+			return n
+		})
+		Expect(n).To(Equal(&parser.Node{Type: parser.NFnSqrt}))
+	})
+})
@@ -0,0 +1,169 @@
+// Package printer converts an ast back into an expression.
+package printer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/relnod/calcgo/parser"
+)
+
+// symbols maps operators and functions to their string representation.
+var symbols = map[parser.NodeType]string{
+	parser.NAdd:  "+",
+	parser.NSub:  "-",
+	parser.NMult: "*",
+	parser.NDiv:  "/",
+	parser.NMod:  "%",
+	parser.NOr:   "|",
+	parser.NXor:  "^",
+	parser.NAnd:  "&",
+
+	parser.NShiftLeft:          "<<",
+	parser.NShiftRight:         ">>",
+	parser.NUnsignedShiftRight: ">>>",
+
+	parser.NFnSqrt: "sqrt",
+	parser.NFnSin:  "sin",
+	parser.NFnCos:  "cos",
+	parser.NFnTan:  "tan",
+
+	parser.NFnGamma:   "gamma",
+	parser.NFnIsPrime: "isprime",
+	parser.NFnBinom:   "binom",
+	parser.NFnPerm:    "perm",
+	parser.NFnGcd:     "gcd",
+	parser.NFnLcm:     "lcm",
+	parser.NFnMod:     "mod",
+
+	parser.NPercent:   "%",
+	parser.NFactorial: "!",
+	parser.NBitNot:    "~",
+}
+
+// Print converts an ast into an expression. Operators are separated by a
+// single whitespace and brackets are only added, where they are needed to keep
+// the structure of the ast.
+//
+// Example:
+//  ast, _ := parser.Parse("((1 + 2))  *   3")
+//  printer.Print(&ast) // Result: "(1 + 2) * 3"
+func Print(ast parser.IAST) string {
+	if ast == nil {
+		return ""
+	}
+
+	return PrintNode(ast.Root())
+}
+
+// PrintNode converts a node and all of its child nodes into an expression.
+func PrintNode(n parser.INode) string {
+	var b strings.Builder
+	printNode(&b, n)
+
+	return b.String()
+}
+
+// Format parses an expression and prints it again. This normalizes the
+// whitespace and removes unneeded brackets.
+func Format(str string) (string, []error) {
+	ast, errors := parser.Parse(str)
+	if errors != nil {
+		return "", errors
+	}
+
+	return Print(&ast), nil
+}
+
+// printNode recursively prints a node.
+func printNode(b *strings.Builder, n parser.INode) {
+	if parser.IsNil(n) {
+		return
+	}
+
+	if parser.IsOperator(n) {
+		printOperator(b, n)
+		return
+	}
+
+	if isPostfix(n) {
+		arg := n.Left()
+		printChild(b, arg, !parser.IsNil(arg) && (parser.IsOperator(arg) || isPrefix(arg)))
+		b.WriteString(symbols[n.GetType()])
+		return
+	}
+
+	if isPrefix(n) {
+		b.WriteString(symbols[n.GetType()])
+		printChild(b, n.Left(), !parser.IsNil(n.Left()) && parser.IsOperator(n.Left()))
+		return
+	}
+
+	if parser.IsFunction(n) {
+		b.WriteString(symbols[n.GetType()])
+		b.WriteString("(")
+		printNode(b, n.Left())
+		if parser.IsBinaryFunction(n) {
+			b.WriteString(", ")
+			printNode(b, n.Right())
+		}
+		b.WriteString(")")
+		return
+	}
+
+	b.WriteString(literal(n))
+}
+
+// printOperator prints an operator and its child nodes. A child node gets
+// surrounded by brackets, if its operator has a lower precedence. Because all
+// operators are left associative, the right child also needs brackets, if its
+// operator has the same precedence.
+func printOperator(b *strings.Builder, n parser.INode) {
+	precedence := parser.Precedence(n.GetType())
+
+	left := n.Left()
+	leftParens := !parser.IsNil(left) && parser.IsOperator(left) &&
+		parser.Precedence(left.GetType()) < precedence
+	printChild(b, left, leftParens)
+
+	b.WriteString(" ")
+	b.WriteString(symbols[n.GetType()])
+	b.WriteString(" ")
+
+	right := n.Right()
+	rightParens := !parser.IsNil(right) && parser.IsOperator(right) &&
+		parser.Precedence(right.GetType()) <= precedence
+	printChild(b, right, rightParens)
+}
+
+// printChild prints a child node, optionally surrounded by brackets.
+func printChild(b *strings.Builder, n parser.INode, parens bool) {
+	if parens {
+		b.WriteString("(")
+	}
+	printNode(b, n)
+	if parens {
+		b.WriteString(")")
+	}
+}
+
+// literal returns the string representation of a literal. The value of
+// optimized nodes gets formatted as a decimal number.
+func literal(n parser.INode) string {
+	if c, ok := n.(parser.IConstant); ok {
+		return strconv.FormatFloat(c.Constant(), 'f', -1, 64)
+	}
+
+	return n.GetValue()
+}
+
+// isPostfix returns true if n is a postfix operator.
+func isPostfix(n parser.INode) bool {
+	return n.GetType() == parser.NPercent || n.GetType() == parser.NFactorial
+}
+
+// isPrefix returns true if n is a prefix operator.
+func isPrefix(n parser.INode) bool {
+	return n.GetType() == parser.NBitNot
+}
+
@@ -0,0 +1,94 @@
+package printer_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/parser"
+	"github.com/relnod/calcgo/printer"
+)
+
+func TestPrinter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Printer Suite")
+}
+
+var _ = Describe("Format()", func() {
+	DescribeTable("formats",
+		func(in string, expected string) {
+			out, errs := printer.Format(in)
+			Expect(errs).To(BeNil())
+			Expect(out).To(Equal(expected))
+		},
+		Entry("empty", "", ""),
+		Entry("int", "1", "1"),
+		Entry("negative dec", "-1.5", "-1.5"),
+		Entry("bin", "0b101", "0b101"),
+		Entry("hex", "0x1F", "0x1F"),
+		Entry("exp", "2^3", "2^3"),
+		Entry("variable", "abc", "abc"),
+		Entry("operators", "1 + 2 - 3 * 4 / 5 % 6 | 7 ^ 8 & 9", "1 + 2 - 3 * 4 / 5 % 6 | 7 ^ 8 & 9"),
+		Entry("whitespace", "  1   +  (2 )  ", "1 + 2"),
+		Entry("unneeded brackets", "((1 + 2))", "1 + 2"),
+		Entry("precedence", "(1 * 2) + 3", "1 * 2 + 3"),
+		Entry("needed brackets", "(1 + 2) * 3", "(1 + 2) * 3"),
+		Entry("right brackets", "1 - (2 - 3)", "1 - (2 - 3)"),
+		Entry("right brackets same precedence", "1 / (2 * 3)", "1 / (2 * 3)"),
+		Entry("left associative", "(1 - 2) - 3", "1 - 2 - 3"),
+		Entry("nested", "((2 + 3) / (1 + 2)) * 3", "(2 + 3) / (1 + 2) * 3"),
+		Entry("functions", "sqrt( (1 + 2) ) * sin(a)", "sqrt(1 + 2) * sin(a)"),
+		Entry("nested functions", "cos(tan(1))", "cos(tan(1))"),
+		Entry("percent", "200+15% - ( a*b )%", "200 + 15% - (a * b)%"),
+		Entry("factorial", "(n-1)! * 2!", "(n - 1)! * 2!"),
+		Entry("functions with two arguments", "binom( n,k )+gcd((a), b*2)", "binom(n, k) + gcd(a, b * 2)"),
+		Entry("shifts", "a<<2>>b>>>(1+c)", "a << 2 >> b >>> (1 + c)"),
+		Entry("bitwise not", "~a & ~( b|c ) + ~~1", "~a & ~(b | c) + ~~1"),
+		Entry("bitwise not and postfix operators", "~n! + (~n)!", "~n! + (~n)!"),
+	)
+
+	It("returns parser errors", func() {
+		out, errs := printer.Format("1 + $")
+		Expect(out).To(BeEmpty())
+		Expect(errs).To(Equal([]error{parser.ErrorExpectedNumberOrVariable}))
+	})
+
+	DescribeTable("printed expressions result in the same ast",
+		func(in string) {
+			ast, errs := parser.Parse(in)
+			Expect(errs).To(BeNil())
+
+			ast2, errs := parser.Parse(printer.Print(&ast))
+			Expect(errs).To(BeNil())
+			Expect(ast2).To(Equal(ast))
+		},
+		Entry("1", "1 + 2 * 3 - 4"),
+		Entry("2", "(1 + 2) * (3 - 4)"),
+		Entry("3", "1 - (2 - (3 - 4))"),
+		Entry("4", "1 * 2 + 3 * 4 * 5"),
+		Entry("5", "((1 + 2) * 3 + 4) / (5 % (6 & 7))"),
+		Entry("6", "sqrt(a * (b + c)) - sin(1) / cos(2 - x)"),
+		Entry("7", "1 + (2) * 3"),
+		Entry("8", "-1 - -2 * (0x1F | 0b1)"),
+		Entry("9", "(1 + 2)% * 3% - sqrt(a)%"),
+		Entry("10", "(n - 1)! / (2 * k)! + mod(perm(n, k), 7)"),
+		Entry("11", "~(a << 4 | b) & ~0xff >>> 2 - (~c)!"),
+	)
+
+	It("prints optimized asts", func() {
+		ast, errs := parser.Parse("(1 + 2) * a + 1 / 4")
+		Expect(errs).To(BeNil())
+		oast, err := optimizer.Optimize(&ast)
+		Expect(err).To(BeNil())
+
+		Expect(printer.Print(oast)).To(Equal("3 * a + 0.25"))
+	})
+
+	It("prints nil asts", func() {
+		Expect(printer.Print(nil)).To(BeEmpty())
+		Expect(printer.Print(&parser.AST{})).To(BeEmpty())
+	})
+})
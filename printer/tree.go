@@ -0,0 +1,166 @@
+package printer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/relnod/calcgo/parser"
+)
+
+// Tree renders an ast as an indented tree. Missing child nodes are rendered as
+// "<nil>" and constants, that were folded by the optimizer, are marked with
+// "(folded)".
+//
+// Example:
+//  ast, _ := parser.Parse("1 + 2 * 3")
+//  printer.Tree(&ast)
+// Result:
+//  +
+//  |-- 1
+//  `-- *
+//      |-- 2
+//      `-- 3
+func Tree(ast parser.IAST) string {
+	if parser.IsEmpty(ast) {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(label(ast.Root()))
+	b.WriteString("\n")
+	writeTreeChilds(&b, ast.Root(), "")
+
+	return b.String()
+}
+
+// writeTreeChilds recursively writes the child nodes of n. Each line is
+// prefixed with the given indentation.
+func writeTreeChilds(b *strings.Builder, n parser.INode, indent string) {
+	childs := childNodes(n)
+	for i, child := range childs {
+		branch, next := "|-- ", "|   "
+		if i == len(childs)-1 {
+			branch, next = "`-- ", "    "
+		}
+
+		b.WriteString(indent)
+		b.WriteString(branch)
+		if parser.IsNil(child) {
+			b.WriteString("<nil>\n")
+			continue
+		}
+
+		b.WriteString(label(child))
+		b.WriteString("\n")
+		writeTreeChilds(b, child, indent+next)
+	}
+}
+
+// Dot renders an ast in the DOT language of Graphviz. Constants, that were
+// folded by the optimizer, are filled.
+//
+// Example:
+//  ast, _ := parser.Parse("1 + 2")
+//  printer.Dot(&ast)
+// Result:
+//  digraph AST {
+//  	ordering=out;
+//  	n0 [label="+"];
+//  	n1 [label="1"];
+//  	n0 -> n1;
+//  	n2 [label="2"];
+//  	n0 -> n2;
+//  }
+func Dot(ast parser.IAST) string {
+	var b strings.Builder
+	b.WriteString("digraph AST {\n")
+	b.WriteString("\tordering=out;\n")
+
+	if !parser.IsEmpty(ast) {
+		id := 0
+		writeDotNode(&b, ast.Root(), &id)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// writeDotNode recursively writes the node n and the edges to its child nodes.
+// Returns the name of the node.
+func writeDotNode(b *strings.Builder, n parser.INode, id *int) string {
+	name := "n" + strconv.Itoa(*id)
+	*id++
+
+	b.WriteString("\t" + name + " [")
+	switch {
+	case parser.IsNil(n):
+		b.WriteString("label=\"<nil>\", style=dashed")
+	case isFolded(n):
+		b.WriteString("label=" + strconv.Quote(label(n)) + ", style=filled, fillcolor=lightblue")
+	default:
+		b.WriteString("label=" + strconv.Quote(label(n)))
+	}
+	b.WriteString("];\n")
+
+	if parser.IsNil(n) {
+		return name
+	}
+
+	for _, child := range childNodes(n) {
+		childName := writeDotNode(b, child, id)
+		b.WriteString("\t" + name + " -> " + childName + ";\n")
+	}
+
+	return name
+}
+
+// childNodes returns the child nodes of n, including missing ones. Operators
+// and functions with two arguments always have two and other functions one
+// child node.
+func childNodes(n parser.INode) []parser.INode {
+	switch {
+	case parser.IsOperator(n), parser.IsBinaryFunction(n):
+		return []parser.INode{n.Left(), n.Right()}
+	case parser.IsFunction(n):
+		return []parser.INode{n.Left()}
+	}
+
+	var childs []parser.INode
+	if !parser.IsNil(n.Left()) {
+		childs = append(childs, n.Left())
+	}
+	if !parser.IsNil(n.Right()) {
+		childs = append(childs, n.Right())
+	}
+
+	return childs
+}
+
+// label returns the label of a single node. A percent sign gets its own label,
+// so it can't be confused with the modulo operator.
+func label(n parser.INode) string {
+	if isFolded(n) {
+		return literal(n) + " (folded)"
+	}
+
+	if n.GetType() == parser.NPercent {
+		return "%(postfix)"
+	}
+
+	if symbol, ok := symbols[n.GetType()]; ok {
+		return symbol
+	}
+
+	if parser.IsLiteral(n) {
+		return n.GetValue()
+	}
+
+	return n.GetType().String() + " " + strconv.Quote(n.GetValue())
+}
+
+// isFolded returns true if n is a constant, that was folded by the optimizer.
+func isFolded(n parser.INode) bool {
+	_, ok := n.(parser.IConstant)
+	return ok
+}
@@ -0,0 +1,117 @@
+package printer_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/parser"
+	"github.com/relnod/calcgo/printer"
+)
+
+var _ = Describe("Tree()", func() {
+	DescribeTable("renders parsed asts",
+		func(in string, expected string) {
+			ast, _ := parser.Parse(in)
+			Expect(printer.Tree(&ast)).To(Equal(expected))
+		},
+		Entry("empty", "", ""),
+		Entry("number", "1", "1\n"),
+		Entry("operator", "1 + a", "+\n|-- 1\n`-- a\n"),
+		Entry("precedence", "1 + 2 * 3",
+			"+\n"+
+				"|-- 1\n"+
+				"`-- *\n"+
+				"    |-- 2\n"+
+				"    `-- 3\n"),
+		Entry("left nested", "1 * 2 - 3",
+			"-\n"+
+				"|-- *\n"+
+				"|   |-- 1\n"+
+				"|   `-- 2\n"+
+				"`-- 3\n"),
+		Entry("function", "sqrt(1 + 2)",
+			"sqrt\n"+
+				"`-- +\n"+
+				"    |-- 1\n"+
+				"    `-- 2\n"),
+		Entry("percent and modulo", "5% % 2",
+			"%\n"+
+				"|-- %(postfix)\n"+
+				"|   `-- 5\n"+
+				"`-- 2\n"),
+		Entry("missing child", "1 +", "+\n|-- 1\n`-- <nil>\n"),
+		Entry("invalid number", "1a", "InvalidNumber \"a\"\n"),
+	)
+
+	It("highlights folded constants", func() {
+		ast, _ := parser.Parse("(1 + 3) * a")
+		oast, err := optimizer.Optimize(&ast)
+		Expect(err).To(BeNil())
+
+		Expect(printer.Tree(oast)).To(Equal("*\n|-- 4 (folded)\n`-- a\n"))
+	})
+
+	It("highlights all constants", func() {
+		ast := &parser.AST{Node: &parser.Node{
+			Type:       parser.NAdd,
+			LeftChild:  &parser.Node{Type: parser.NVar, Value: "a"},
+			RightChild: &constant{Node: parser.Node{Type: parser.NDec}, value: 0.5},
+		}}
+
+		Expect(printer.Tree(ast)).To(Equal("+\n|-- a\n`-- 0.5 (folded)\n"))
+	})
+})
+
+// constant is a node with a calculated value, that doesn't come from the
+// optimizer.
+type constant struct {
+	parser.Node
+	value float64
+}
+
+// Constant returns the value of the constant.
+func (c *constant) Constant() float64 { return c.value }
+
+var _ = Describe("Dot()", func() {
+	It("renders an empty ast", func() {
+		Expect(printer.Dot(&parser.AST{})).To(Equal("digraph AST {\n\tordering=out;\n}\n"))
+	})
+
+	It("renders a parsed ast", func() {
+		ast, _ := parser.Parse("1 + sqrt(a)")
+		Expect(printer.Dot(&ast)).To(Equal(`digraph AST {
+	ordering=out;
+	n0 [label="+"];
+	n1 [label="1"];
+	n0 -> n1;
+	n2 [label="sqrt"];
+	n3 [label="a"];
+	n2 -> n3;
+	n0 -> n2;
+}
+`))
+	})
+
+	It("renders missing child nodes", func() {
+		ast, _ := parser.Parse("1 +")
+		Expect(printer.Dot(&ast)).To(ContainSubstring(`n2 [label="<nil>", style=dashed];`))
+	})
+
+	It("highlights folded constants", func() {
+		ast, _ := parser.Parse("(1 + 3) * a")
+		oast, err := optimizer.Optimize(&ast)
+		Expect(err).To(BeNil())
+
+		Expect(printer.Dot(oast)).To(Equal(`digraph AST {
+	ordering=out;
+	n0 [label="*"];
+	n1 [label="4 (folded)", style=filled, fillcolor=lightblue];
+	n0 -> n1;
+	n2 [label="a"];
+	n0 -> n2;
+}
+`))
+	})
+})
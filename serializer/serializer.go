@@ -0,0 +1,174 @@
+// Package serializer converts asts to JSON and back.
+//
+// Node types are stored by their name (see parser.NodeType.String), so encoded
+// asts stay valid, when new node types get added. Every encoded ast contains
+// the version of the format.
+//
+// Example:
+//  {"version":1,"root":{"type":"Add","left":{"type":"Int","value":"1"},"right":{"type":"Var","value":"a"}}}
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/parser"
+)
+
+// Version is the version of the format. It gets incremented, when the format
+// changes in an incompatible way.
+const Version = 1
+
+// Errors, that can occur during serialization
+var (
+	ErrorUnsupportedVersion = errors.New("Error: Unsupported version")
+	ErrorUnknownNodeType    = errors.New("Error: Unknown node type")
+	ErrorInvalidNode        = errors.New("Error: Invalid node")
+	ErrorInvalidNumber      = errors.New("Error: Invalid number")
+)
+
+// document is the encoded form of an ast.
+type document struct {
+	Version   int   `json:"version"`
+	Optimized bool  `json:"optimized,omitempty"`
+	Root      *node `json:"root,omitempty"`
+}
+
+// node is the encoded form of a node. Optimized nodes store their value in
+// Number instead of Value.
+type node struct {
+	Type   string `json:"type"`
+	Value  string `json:"value,omitempty"`
+	Number string `json:"number,omitempty"`
+	Left   *node  `json:"left,omitempty"`
+	Right  *node  `json:"right,omitempty"`
+}
+
+// Marshal converts an ast to JSON. Optimized asts are supported as well.
+func Marshal(ast parser.IAST) ([]byte, error) {
+	doc := document{Version: Version}
+
+	if ast != nil {
+		root, err := encodeNode(ast.Root())
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Optimized = ast.Optimized()
+		doc.Root = root
+	}
+
+	return json.Marshal(doc)
+}
+
+// Unmarshal converts JSON created by Marshal back to an ast. Returns a
+// *parser.AST or an *optimizer.OptimizedAST depending on the encoded ast.
+func Unmarshal(data []byte) (parser.IAST, error) {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Version != Version {
+		return nil, ErrorUnsupportedVersion
+	}
+
+	root, err := decodeNode(doc.Root, doc.Optimized)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Optimized {
+		return &optimizer.OptimizedAST{Node: root}, nil
+	}
+
+	if root == nil {
+		return &parser.AST{}, nil
+	}
+
+	return &parser.AST{Node: root.(*parser.Node)}, nil
+}
+
+// encodeNode recursively encodes a node.
+func encodeNode(n parser.INode) (*node, error) {
+	if parser.IsNil(n) {
+		return nil, nil
+	}
+
+	t := n.GetType().String()
+	if _, ok := parser.NodeTypeFromString(t); !ok {
+		return nil, ErrorUnknownNodeType
+	}
+
+	if o, ok := n.(*optimizer.OptimizedNode); ok {
+		return &node{
+			Type:   t,
+			Number: strconv.FormatFloat(o.Value, 'g', -1, 64),
+		}, nil
+	}
+
+	if _, ok := n.(*parser.Node); !ok {
+		return nil, ErrorInvalidNode
+	}
+
+	left, err := encodeNode(n.Left())
+	if err != nil {
+		return nil, err
+	}
+	right, err := encodeNode(n.Right())
+	if err != nil {
+		return nil, err
+	}
+
+	return &node{
+		Type:  t,
+		Value: n.GetValue(),
+		Left:  left,
+		Right: right,
+	}, nil
+}
+
+// decodeNode recursively decodes a node. Optimized nodes are only allowed, if
+// optimized is true.
+func decodeNode(n *node, optimized bool) (parser.INode, error) {
+	if n == nil {
+		return nil, nil
+	}
+
+	t, ok := parser.NodeTypeFromString(n.Type)
+	if !ok {
+		return nil, ErrorUnknownNodeType
+	}
+
+	if n.Number != "" {
+		if !optimized || n.Value != "" || n.Left != nil || n.Right != nil {
+			return nil, ErrorInvalidNode
+		}
+
+		value, err := strconv.ParseFloat(n.Number, 64)
+		if err != nil {
+			return nil, ErrorInvalidNumber
+		}
+
+		return &optimizer.OptimizedNode{Type: t, Value: value}, nil
+	}
+
+	left, err := decodeNode(n.Left, optimized)
+	if err != nil {
+		return nil, err
+	}
+	right, err := decodeNode(n.Right, optimized)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parser.Node{
+		Type:       t,
+		Value:      n.Value,
+		LeftChild:  left,
+		RightChild: right,
+	}, nil
+}
+
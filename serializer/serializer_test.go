@@ -0,0 +1,154 @@
+package serializer_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/relnod/calcgo/interpreter/optimizer"
+	"github.com/relnod/calcgo/parser"
+	"github.com/relnod/calcgo/serializer"
+)
+
+func TestSerializer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Serializer Suite")
+}
+
+var _ = Describe("Serializer", func() {
+	DescribeTable("round trips parsed asts",
+		func(str string) {
+			ast, errs := parser.Parse(str)
+			Expect(errs).To(BeNil())
+
+			data, err := serializer.Marshal(&ast)
+			Expect(err).To(BeNil())
+
+			decoded, err := serializer.Unmarshal(data)
+			Expect(err).To(BeNil())
+			Expect(decoded).To(Equal(&ast))
+		},
+		Entry("empty", ""),
+		Entry("number", "1"),
+		Entry("all literals", "1 + 1.5 - 0b101 * 0x1F / 2^3 % abc"),
+		Entry("all operators", "1 | 2 ^ 3 & 4"),
+		Entry("brackets", "(1 + 2) * (3 - a)"),
+		Entry("functions", "sqrt(a) + sin(1) - cos(2) * tan(b)"),
+		Entry("nested", "sqrt((1 + (2 * (3 + a))))"),
+	)
+
+	It("round trips asts with parser errors", func() {
+		ast, errs := parser.Parse("1 + (2 $")
+		Expect(errs).NotTo(BeNil())
+
+		data, err := serializer.Marshal(&ast)
+		Expect(err).To(BeNil())
+
+		decoded, err := serializer.Unmarshal(data)
+		Expect(err).To(BeNil())
+		Expect(decoded).To(Equal(&ast))
+	})
+
+	DescribeTable("round trips optimized asts",
+		func(str string) {
+			ast, errs := parser.Parse(str)
+			Expect(errs).To(BeNil())
+			oast, err := optimizer.Optimize(&ast)
+			Expect(err).To(BeNil())
+
+			data, err := serializer.Marshal(oast)
+			Expect(err).To(BeNil())
+
+			decoded, err := serializer.Unmarshal(data)
+			Expect(err).To(BeNil())
+			Expect(decoded).To(Equal(oast))
+		},
+		Entry("constant", "1 + 2 * 3"),
+		Entry("fraction", "1 / 3"),
+		Entry("variables", "(1 + 2) * a + sin(b) / 4"),
+	)
+
+	It("encodes node types by name", func() {
+		ast, errs := parser.Parse("1 + a")
+		Expect(errs).To(BeNil())
+
+		data, err := serializer.Marshal(&ast)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(`{"version":1,"root":{"type":"Add",` +
+			`"left":{"type":"Int","value":"1"},"right":{"type":"Var","value":"a"}}}`))
+	})
+
+	It("encodes optimized nodes as numbers", func() {
+		oast := &optimizer.OptimizedAST{
+			Node: &parser.Node{
+				Type:       parser.NMult,
+				LeftChild:  &optimizer.OptimizedNode{Type: parser.NDec, Value: 0.25},
+				RightChild: &parser.Node{Type: parser.NVar, Value: "a"},
+			},
+		}
+
+		data, err := serializer.Marshal(oast)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(`{"version":1,"optimized":true,"root":{"type":"Mult",` +
+			`"left":{"type":"Dec","number":"0.25"},"right":{"type":"Var","value":"a"}}}`))
+	})
+
+	It("round trips special float values", func() {
+		for _, value := range []float64{math.Inf(1), math.Inf(-1), math.MaxFloat64, math.SmallestNonzeroFloat64} {
+			oast := &optimizer.OptimizedAST{
+				Node: &optimizer.OptimizedNode{Type: parser.NDec, Value: value},
+			}
+
+			data, err := serializer.Marshal(oast)
+			Expect(err).To(BeNil())
+
+			decoded, err := serializer.Unmarshal(data)
+			Expect(err).To(BeNil())
+			Expect(decoded).To(Equal(oast))
+		}
+	})
+
+	It("round trips empty optimized asts", func() {
+		data, err := serializer.Marshal(&optimizer.OptimizedAST{})
+		Expect(err).To(BeNil())
+
+		decoded, err := serializer.Unmarshal(data)
+		Expect(err).To(BeNil())
+		Expect(decoded).To(Equal(&optimizer.OptimizedAST{}))
+	})
+
+	It("marshals nil asts", func() {
+		data, err := serializer.Marshal(nil)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(`{"version":1}`))
+	})
+
+	It("fails to marshal unknown node types", func() {
+		_, err := serializer.Marshal(&parser.AST{Node: &parser.Node{Type: parser.NodeType(1000)}})
+		Expect(err).To(Equal(serializer.ErrorUnknownNodeType))
+	})
+
+	DescribeTable("fails to unmarshal invalid input",
+		func(data string, expErr error) {
+			ast, err := serializer.Unmarshal([]byte(data))
+			Expect(ast).To(BeNil())
+			Expect(err).To(Equal(expErr))
+		},
+		Entry("missing version", `{"root":{"type":"Int","value":"1"}}`, serializer.ErrorUnsupportedVersion),
+		Entry("newer version", `{"version":2}`, serializer.ErrorUnsupportedVersion),
+		Entry("unknown node type", `{"version":1,"root":{"type":"Pow"}}`, serializer.ErrorUnknownNodeType),
+		Entry("node type as number", `{"version":1,"root":{"type":"6"}}`, serializer.ErrorUnknownNodeType),
+		Entry("optimized node in parsed ast", `{"version":1,"root":{"type":"Dec","number":"1"}}`, serializer.ErrorInvalidNode),
+		Entry("optimized node with children", `{"version":1,"optimized":true,"root":{"type":"Dec","number":"1","left":{"type":"Int","value":"1"}}}`, serializer.ErrorInvalidNode),
+		Entry("invalid number", `{"version":1,"optimized":true,"root":{"type":"Dec","number":"abc"}}`, serializer.ErrorInvalidNumber),
+	)
+
+	It("fails to unmarshal invalid json", func() {
+		ast, err := serializer.Unmarshal([]byte(`{"version":`))
+		Expect(ast).To(BeNil())
+		Expect(err).NotTo(BeNil())
+	})
+})
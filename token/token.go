@@ -11,14 +11,17 @@ const (
 
 	literalBeg
 	// Numbers
-	Int // [0-9]+
-	Dec // [0-9]+\.[0-9]+
-	Bin // 0b[01]+
-	Hex // 0x[0-9A-F]+
-	Exp // [0-9]+\^[0-9]+
+	Int      // [0-9]+
+	Dec      // [0-9]*\.[0-9]+
+	Bin      // 0b[01]+
+	Hex      // 0x[0-9a-fA-F]+
+	Exp      // [0-9]+\^[0-9]+
+	Oct      // 0o[0-7]+
+	Sci      // [0-9]*(\.[0-9]+)?[eE][+-]?[0-9]+
+	HexFloat // 0x[0-9a-fA-F]*(\.[0-9a-fA-F]*)?[pP][+-]?[0-9]+
 
 	// Variable
-	Var // [a-zA-Z]+
+	Var // [_\pL][_\pL\pN]*
 	literalEnd
 
 	operatorBeg
@@ -31,6 +34,10 @@ const (
 	Or    // "|"
 	Xor   // "^"
 	And   // "&"
+
+	ShiftLeft          // "<<"
+	ShiftRight         // ">>"
+	UnsignedShiftRight // ">>>"
 	operatorEnd
 
 	functionBeg
@@ -39,6 +46,14 @@ const (
 	Sin  // "sin("
 	Cos  // "cos("
 	Tan  // "tan("
+
+	Gamma   // "gamma("
+	IsPrime // "isprime("
+	Binom   // "binom("
+	Perm    // "perm("
+	Gcd     // "gcd("
+	Lcm     // "lcm("
+	IntMod  // "mod("
 	UnkownFunktion
 	functionEnd
 
@@ -46,10 +61,22 @@ const (
 	ParenL // "("
 	ParenR // ")"
 
+	// Separators
+	Comma // ","
+
+	// Postfix operators
+	Percent   // "%" directly after a value
+	Factorial // "!"
+
+	// Prefix operators
+	BitNot // "~"
+
 	// Errors
 	InvalidCharacter
 	InvalidCharacterInNumber
 	InvalidCharacterInVariable
+	InputTooLong
+	ReadError
 )
 
 var tokens = [...]string{
@@ -61,6 +88,10 @@ var tokens = [...]string{
 	Hex: "HexaDecimal",
 	Exp: "Exponential",
 
+	Oct:      "Octal",
+	Sci:      "Scientific",
+	HexFloat: "HexaDecimalFloat",
+
 	Var: "Variable",
 
 	Plus:  "+",
@@ -72,18 +103,39 @@ var tokens = [...]string{
 	Xor:   "^",
 	And:   "&",
 
+	ShiftLeft:          "<<",
+	ShiftRight:         ">>",
+	UnsignedShiftRight: ">>>",
+
 	Sqrt: "sqrt",
 	Sin:  "sin",
 	Cos:  "cos",
 	Tan:  "tan",
 
+	Gamma:   "gamma",
+	IsPrime: "isprime",
+	Binom:   "binom",
+	Perm:    "perm",
+	Gcd:     "gcd",
+	Lcm:     "lcm",
+	IntMod:  "mod",
+
 	ParenL: "(",
 	ParenR: ")",
 
+	Comma: ",",
+
+	Percent:   "Percent",
+	Factorial: "!",
+
+	BitNot: "~",
+
 	InvalidCharacter:           "Invalid Character",
 	InvalidCharacterInNumber:   "Invalid character in number",
 	InvalidCharacterInVariable: "Invalid character in Variabl",
 	UnkownFunktion:             "Unknown function",
+	InputTooLong:               "Input too long",
+	ReadError:                  "Read error",
 }
 
 // Token represents a token returned by the lexer